@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// siteConfig is a pssg.yaml static-site config. Only the small subset of
+// YAML that site configs use is understood: nested maps of scalars, with
+// comments. List items and block scalars are skipped. Edits are made in
+// place, so comments and formatting elsewhere in the file survive a save.
+//
+// graph2md reads these keys:
+//
+//	paths.content             output directory (written back after generation)
+//	repo.name                 repository name
+//	repo.url                  repository URL
+//	repo.branch               branch used in source links
+//	repo.source_url_template  source link preset or URL template
+//	site.base_path            URL prefix for internal links
+//	site.link_style           internal link style
+//	site.layout               output layout
+//	repos.<name>.*            input, url and branch of each repo in a multi-repo site
+type siteConfig struct {
+	path  string
+	lines []string
+
+	values   map[string]string // dotted key -> scalar value
+	valueAt  map[string]int    // dotted key -> line index
+	section  map[string]int    // dotted key of a map -> line index of its header
+	indentOf map[string]int    // dotted key -> indentation of its line
+	childOf  map[string]int    // dotted key of a map ("" for the root) -> indentation of its entries
+}
+
+func loadSiteConfig(path string) (*siteConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &siteConfig{path: path, lines: strings.Split(string(data), "\n")}
+	if err := c.parse(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *siteConfig) parse() error {
+	c.values = make(map[string]string)
+	c.valueAt = make(map[string]int)
+	c.section = make(map[string]int)
+	c.indentOf = make(map[string]int)
+	c.childOf = make(map[string]int)
+
+	type level struct {
+		indent int
+		key    string
+	}
+	var stack []level
+	blockIndent := -1 // indentation of a block scalar's key, while skipping it
+
+	for i, line := range c.lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockIndent >= 0 {
+			if indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" || trimmed == "---" {
+			continue
+		}
+
+		key, rest, ok := strings.Cut(trimmed, ":")
+		if !ok || (rest != "" && rest[0] != ' ') {
+			return fmt.Errorf("line %d: expected \"key: value\"", i+1)
+		}
+		key = strings.Trim(key, `"'`)
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		parts := make([]string, 0, len(stack)+1)
+		for _, l := range stack {
+			parts = append(parts, l.key)
+		}
+		parent := strings.Join(parts, ".")
+		if want, ok := c.childOf[parent]; !ok {
+			c.childOf[parent] = indent
+		} else if indent != want {
+			return fmt.Errorf("line %d: indented %d spaces, want %d like the lines before it", i+1, indent, want)
+		}
+		dotted := strings.Join(append(parts, key), ".")
+		c.indentOf[dotted] = indent
+
+		value := stripComment(strings.TrimSpace(rest))
+		switch {
+		case value == "":
+			c.section[dotted] = i
+			stack = append(stack, level{indent: indent, key: key})
+		case value[0] == '|' || value[0] == '>':
+			blockIndent = indent
+		default:
+			c.values[dotted] = unquote(value)
+			c.valueAt[dotted] = i
+		}
+	}
+	return nil
+}
+
+// get returns the first non-empty value among keys.
+func (c *siteConfig) get(keys ...string) string {
+	for _, k := range keys {
+		if v := c.values[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// repoInputs returns an input for each repos.<name> section that names an
+// input file, in name order.
+func (c *siteConfig) repoInputs() []input {
+	var names []string
+	for key := range c.values {
+		if rest, ok := strings.CutPrefix(key, "repos."); ok {
+			if name, field, ok := strings.Cut(rest, "."); ok && field == "input" {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	inputs := make([]input, 0, len(names))
+	for _, name := range names {
+		inputs = append(inputs, input{repo: name, path: c.values["repos."+name+".input"]})
+	}
+	return inputs
+}
+
+// set assigns a scalar to a dotted key, rewriting its line if present and
+// otherwise inserting it under the nearest existing parent map.
+func (c *siteConfig) set(key, value string) {
+	quoted := strconv.Quote(value)
+
+	if i, ok := c.valueAt[key]; ok {
+		line := c.lines[i]
+		indent := line[:c.indentOf[key]]
+		name, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+		_, rest, _ := strings.Cut(line, ":")
+		rest = strings.TrimSpace(rest)
+		comment := ""
+		if v := stripComment(rest); v != rest {
+			comment = " " + strings.TrimSpace(rest[len(v):])
+		}
+		c.lines[i] = fmt.Sprintf("%s%s: %s%s", indent, name, quoted, comment)
+		c.parse()
+		return
+	}
+
+	// Find the deepest existing parent map and insert the missing levels
+	// directly below its header, indented like the map's other entries.
+	parts := strings.Split(key, ".")
+	insertAt, indent, depth := len(c.lines), c.childOf[""], 0
+	step := 2
+	for n := len(parts) - 1; n > 0; n-- {
+		parent := strings.Join(parts[:n], ".")
+		if i, ok := c.section[parent]; ok {
+			insertAt, indent, depth = i+1, c.indentOf[parent]+step, n
+			if child, ok := c.childOf[parent]; ok {
+				indent = child
+				step = child - c.indentOf[parent]
+			}
+			break
+		}
+	}
+	if depth == 0 {
+		// Append at the end, before any trailing blank line.
+		for insertAt > 0 && strings.TrimSpace(c.lines[insertAt-1]) == "" {
+			insertAt--
+		}
+	}
+
+	var added []string
+	for j := depth; j < len(parts); j++ {
+		pad := strings.Repeat(" ", indent+step*(j-depth))
+		if j == len(parts)-1 {
+			added = append(added, fmt.Sprintf("%s%s: %s", pad, parts[j], quoted))
+		} else {
+			added = append(added, fmt.Sprintf("%s%s:", pad, parts[j]))
+		}
+	}
+	lines := append([]string{}, c.lines[:insertAt]...)
+	lines = append(lines, added...)
+	c.lines = append(lines, c.lines[insertAt:]...)
+	c.parse()
+}
+
+func (c *siteConfig) save() error {
+	return os.WriteFile(c.path, []byte(strings.Join(c.lines, "\n")), 0644)
+}
+
+// stripComment removes a trailing " # comment" outside of quotes.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == '\\' && quote == '"' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#' && (i == 0 || s[i-1] == ' '):
+			return strings.TrimSpace(s[:i])
+		}
+	}
+	return s
+}
+
+// unquote strips YAML single or double quotes from a scalar.
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSiteConfigSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		key   string
+		want  string // expected file content after set
+	}{
+		{
+			name:  "existing key",
+			input: "paths:\n    content: ./old # where pages go\n    static: ./static\n",
+			key:   "paths.content",
+			want:  "paths:\n    content: \"data\" # where pages go\n    static: ./static\n",
+		},
+		{
+			name:  "missing key in four-space section",
+			input: "site:\n    title: Docs\npaths:\n    static: ./static\n",
+			key:   "paths.content",
+			want:  "site:\n    title: Docs\npaths:\n    content: \"data\"\n    static: ./static\n",
+		},
+		{
+			name:  "missing key in two-space section",
+			input: "paths:\n  static: ./static\n",
+			key:   "paths.content",
+			want:  "paths:\n  content: \"data\"\n  static: ./static\n",
+		},
+		{
+			name:  "missing section",
+			input: "site:\n    title: Docs\n",
+			key:   "paths.content",
+			want:  "site:\n    title: Docs\npaths:\n  content: \"data\"\n",
+		},
+		{
+			name:  "missing levels below four-space section",
+			input: "repos:\n    api:\n        input: api.json\n",
+			key:   "repos.web.url",
+			want:  "repos:\n    web:\n        url: \"data\"\n    api:\n        input: api.json\n",
+		},
+		{
+			name:  "empty section",
+			input: "paths:\nsite:\n    title: Docs\n",
+			key:   "paths.content",
+			want:  "paths:\n  content: \"data\"\nsite:\n    title: Docs\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pssg.yaml")
+			if err := os.WriteFile(path, []byte(tt.input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c, err := loadSiteConfig(path)
+			if err != nil {
+				t.Fatalf("loading: %v", err)
+			}
+			c.set(tt.key, "data")
+			if err := c.save(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data); got != tt.want {
+				t.Errorf("content after set:\n%s\nwant:\n%s", got, tt.want)
+			}
+			// The file must still parse, with the new value and the
+			// values it already had.
+			reloaded, err := loadSiteConfig(path)
+			if err != nil {
+				t.Fatalf("reloading: %v", err)
+			}
+			if got := reloaded.get(tt.key); got != "data" {
+				t.Errorf("%s = %q after reload, want \"data\"", tt.key, got)
+			}
+			for k, v := range c.values {
+				if k != tt.key && reloaded.get(k) != v {
+					t.Errorf("%s = %q after reload, want %q", k, reloaded.get(k), v)
+				}
+			}
+		})
+	}
+}
+
+func TestSiteConfigRejectsMisalignedKeys(t *testing.T) {
+	c := &siteConfig{lines: strings.Split("paths:\n  content: data\n    static: ./static\n", "\n")}
+	if err := c.parse(); err == nil {
+		t.Error("parse accepted keys of one map at different indentation")
+	}
+}
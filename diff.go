@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/supermodeltools/graph2md/pkg/graph2md"
+)
+
+// runDiff implements "graph2md diff": it compares two graph snapshots and
+// writes a changelog page and a machine-readable JSON diff.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	oldPath := fs.String("old", "", "Older graph snapshot")
+	newPath := fs.String("new", "", "Newer graph snapshot")
+	outputDir := fs.String("output", "data", "Output directory for the changelog page")
+	changelog := fs.String("changelog", "changelog.md", "Changelog page, relative to -output (empty to skip)")
+	jsonPath := fs.String("json", "-", "JSON diff output file; - writes to standard output, empty skips it")
+	repoName := fs.String("repo", "supermodel-public-api", "Repository name")
+	basePath := fs.String("base-path", "", "URL path prefix for internal links (e.g. /docs)")
+	linkStyle := fs.String("links", "html", "Internal link style: html, pretty or md")
+	markdownLinks := fs.Bool("markdown-links", false, "Write [label](url) Markdown links instead of HTML <a> tags")
+	layoutName := fs.String("layout", "flat", "Output layout of the linked pages: flat, type, domain or source")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: graph2md diff -old a.json -new b.json [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Compares two graph snapshots and writes a changelog page and a JSON diff.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *oldPath == "" || *newPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	links, err := graph2md.ParseLinkStyle(*linkStyle)
+	if err != nil {
+		log.Fatal(err)
+	}
+	layout, err := graph2md.ParseLayout(*layoutName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Loading old graph from %s...", *oldPath)
+	oldGraph, err := graph2md.Load(*oldPath)
+	if err != nil {
+		log.Fatalf("loading %s: %v", *oldPath, err)
+	}
+	log.Printf("Loading new graph from %s...", *newPath)
+	newGraph, err := graph2md.Load(*newPath)
+	if err != nil {
+		log.Fatalf("loading %s: %v", *newPath, err)
+	}
+
+	d := graph2md.DiffGraphs(oldGraph, newGraph)
+	log.Printf("Diff: %d added, %d removed, %d moved, %d reassigned, %d edges added, %d edges removed",
+		len(d.Added), len(d.Removed), len(d.Moved), len(d.Reassigned), len(d.AddedEdges), len(d.RemovedEdges))
+
+	if *changelog != "" {
+		nodes, rels := newGraph.Graph.Nodes, newGraph.Graph.Relationships
+		idx := graph2md.BuildIndex(nodes, rels)
+		entries, slugs := graph2md.AssignSlugs(nodes)
+		r := &graph2md.Renderer{
+			Index:         idx,
+			Slugs:         slugs,
+			Paths:         graph2md.LayoutPaths(layout, entries, idx),
+			RepoName:      *repoName,
+			BasePath:      *basePath,
+			LinkStyle:     links,
+			MarkdownLinks: *markdownLinks,
+			Commit:        d.NewCommit,
+			GeneratedAt:   newGraph.GeneratedAt,
+			Diff:          d,
+		}
+		page := r.RenderChangelog()
+		if err := graph2md.NewWriter(*outputDir, nil).Write(*changelog, page.Content); err != nil {
+			log.Fatalf("writing changelog: %v", err)
+		}
+		log.Printf("Wrote changelog to %s", *changelog)
+	}
+
+	if *jsonPath != "" {
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			log.Fatalf("encoding diff: %v", err)
+		}
+		data = append(data, '\n')
+		if *jsonPath == "-" {
+			_, err = os.Stdout.Write(data)
+		} else {
+			err = os.WriteFile(*jsonPath, data, 0644)
+		}
+		if err != nil {
+			log.Fatalf("writing diff: %v", err)
+		}
+	}
+}
@@ -1,2188 +1,712 @@
 package main
 
 import (
+	"cmp"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"html"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
+	"runtime"
+	"strconv"
 	"strings"
-)
-
-var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
-
-// toSlug converts a string to a URL-safe slug.
-func toSlug(s string) string {
-	s = strings.ToLower(s)
-	s = nonAlnum.ReplaceAllString(s, "-")
-	s = strings.Trim(s, "-")
-	return s
-}
-
-// Graph JSON structures matching Supermodel API response
-
-type APIResponse struct {
-	Status string          `json:"status"`
-	JobID  string          `json:"jobId"`
-	Error  json.RawMessage `json:"error"`
-	Result *GraphResult    `json:"result"`
-}
-
-type GraphResult struct {
-	GeneratedAt string          `json:"generatedAt"`
-	Message     string          `json:"message"`
-	Stats       GraphStats      `json:"stats"`
-	Graph       Graph           `json:"graph"`
-	Metadata    json.RawMessage `json:"metadata"`
-	Domains     []DomainSummary `json:"domains"`
-	Artifacts   []Artifact      `json:"artifacts"`
-}
-
-type GraphStats struct {
-	NodeCount         int            `json:"nodeCount"`
-	RelationshipCount int            `json:"relationshipCount"`
-	NodeTypes         map[string]int `json:"nodeTypes"`
-	RelationshipTypes map[string]int `json:"relationshipTypes"`
-}
-
-type Graph struct {
-	Nodes         []Node         `json:"nodes"`
-	Relationships []Relationship `json:"relationships"`
-}
+	"sync"
 
-type Node struct {
-	ID         string                 `json:"id"`
-	Labels     []string               `json:"labels"`
-	Properties map[string]interface{} `json:"properties"`
-}
+	"github.com/supermodeltools/graph2md/pkg/graph2md"
+)
 
-type Relationship struct {
-	ID         string                 `json:"id"`
-	Type       string                 `json:"type"`
-	StartNode  string                 `json:"startNode"`
-	EndNode    string                 `json:"endNode"`
-	Properties map[string]interface{} `json:"properties"`
+// jobExitCodes are the exit codes for inputs that are API responses of
+// unfinished or failed jobs, so pipelines can tell a job worth polling again
+// from one that failed. Other errors exit with 1.
+var jobExitCodes = map[graph2md.JobState]int{
+	graph2md.JobFailed:     3,
+	graph2md.JobPending:    4,
+	graph2md.JobProcessing: 5,
 }
 
-type DomainSummary struct {
-	Name       string             `json:"name"`
-	Subdomains []SubdomainSummary `json:"subdomains"`
-	Files      []string           `json:"files"`
+// jobExitCode returns the exit code for an input whose response is an
+// unfinished or failed job, or 0 if it has a result that can still be used
+// (a failed job's partial result, unless strict). now reports whether to
+// exit at once rather than skip the input and render the others: with
+// strict, or when it is the only input.
+func jobExitCode(resp *graph2md.APIResponse, strict, only bool) (code int, now bool) {
+	code, ok := jobExitCodes[resp.State()]
+	if !ok || (!strict && resp.Result != nil) {
+		return 0, false
+	}
+	return code, strict || only
 }
 
-type SubdomainSummary struct {
-	Name               string   `json:"name"`
-	DescriptionSummary string   `json:"descriptionSummary"`
-	Files              []string `json:"files"`
-	Functions          []string `json:"functions"`
-	Classes            []string `json:"classes"`
+// command is a graph2md subcommand.
+type command struct {
+	name, summary string
+	run           func(args []string)
 }
 
-type Artifact struct {
-	Type  string          `json:"type"`
-	Name  string          `json:"name"`
-	Graph *Graph          `json:"graph"`
-	Stats json.RawMessage `json:"stats"`
+// commands lists the subcommands. Without one, graph2md runs generate, so
+// command lines from before subcommands existed keep working.
+var commands = []command{
+	{"generate", "Generate markdown pages from graphs (the default)", runGenerate},
+	{"stats", "Print node and relationship counts of graphs", runStats},
+	{"validate", "Check graphs for dangling relationships and other problems", runValidate},
+	{"diff", "Compare two graph snapshots and write a changelog", runDiff},
+	{"query", "List entities matching a type, name, path or domain", runQuery},
 }
 
 func main() {
-	inputFiles := flag.String("input", "", "Comma-separated paths to graph JSON file(s)")
-	outputDir := flag.String("output", "data", "Output directory for markdown files")
-	repoName := flag.String("repo", "supermodel-public-api", "Repository name")
-	repoURL := flag.String("repo-url", "https://github.com/supermodeltools/supermodel-public-api", "Repository URL")
-	flag.Parse()
-
-	if *inputFiles == "" {
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name := args[0]
+		if name == "help" {
+			if len(args) > 1 {
+				// "help <command>" is "<command> -h".
+				name, args = args[1], []string{"-h"}
+			} else {
+				usage(os.Stdout)
+				return
+			}
+		} else {
+			args = args[1:]
+		}
+		for _, c := range commands {
+			if c.name == name {
+				c.run(args)
+				return
+			}
+		}
+		fmt.Fprintf(os.Stderr, "graph2md: unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	runGenerate(args)
+}
+
+// usage lists the subcommands.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: graph2md <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nRun \"graph2md help <command>\" for a command's flags. Without a command, graph2md runs generate.\n")
+}
+
+// runGenerate implements "graph2md generate": it renders a markdown page
+// for every entity in the input graphs.
+func runGenerate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	inputFiles := fs.String("input", "", "Comma-separated paths to graph JSON file(s), optionally gzipped; - reads standard input. Prefix a path with name= to document it as repo name")
+	outputDir := fs.String("output", "data", "Output directory for markdown files")
+	repoName := fs.String("repo", "supermodel-public-api", "Repository name")
+	repoURL := fs.String("repo-url", "https://github.com/supermodeltools/supermodel-public-api", "Repository URL")
+	sourceTemplate := fs.String("source-url-template", "github", "Source link preset (github, gitlab, bitbucket, gitea, azure) or URL template with {repo} {path} {start} {end} {branch} {commit} {ref}")
+	branch := fs.String("branch", "main", "Branch used in source links")
+	commit := fs.String("commit", "", "Commit SHA to pin source links to (default: from graph metadata)")
+	sourceRoot := fs.String("source-root", "", "Local checkout to embed source snippets from")
+	snippetLines := fs.Int("snippet-lines", graph2md.DefaultSnippetLines, "Maximum lines per embedded source snippet")
+	indexPages := fs.Bool("index-pages", true, "Also generate an overview page and per-type index pages")
+	manifestPath := fs.String("manifest", "manifest.json", "Manifest of generated pages, relative to -output (empty to disable)")
+	workers := fs.Int("workers", runtime.NumCPU(), "Number of pages to render and write concurrently")
+	mergeName := fs.String("merge", "first", "How to combine a node found in several inputs: first, last, or deep (merge properties, later values win)")
+	mergeReport := fs.String("merge-report", "", "Write conflicting node properties between inputs to this JSON file")
+	oldGraphPath := fs.String("old", "", "Earlier graph snapshot: adds a Changes section to changed pages and writes changelog.md")
+	strict := fs.Bool("strict", false, "Exit with an error if any input fails to load, is not a successful API response, or any page fails to write")
+	force := fs.Bool("force", false, "Rewrite every page, even if its content is unchanged")
+	prune := fs.String("prune", "", "Remove stale generated pages: delete, or archive (move to -archive-dir)")
+	archiveDir := fs.String("archive-dir", "archive", "Directory stale pages are moved to with -prune archive")
+	pruneDryRun := fs.Bool("prune-dry-run", false, "List stale pages that -prune would remove without touching them")
+	redirectModes := fs.String("redirects", "", "Comma-separated redirect outputs for renamed pages: aliases (Hugo frontmatter), html (meta-refresh stubs), netlify (_redirects)")
+	redirectsDir := fs.String("redirects-dir", "", "Directory for html stubs and _redirects (default: -output)")
+	enrichmentsDir := fs.String("enrichments", "./enrichments", "Directory of enrichment JSON sidecar files (keyed by node ID or slug)")
+	basePath := fs.String("base-path", "", "URL path prefix for internal links (e.g. /docs)")
+	layoutName := fs.String("layout", "flat", "Output layout: flat (<slug>.md), type (functions/<slug>.md), domain (<domain>/<subdomain>/<slug>.md) or source (mirrors source paths)")
+	linkStyle := fs.String("links", "html", "Internal link style: html (/slug.html), pretty (/slug/) or md (relative slug.md)")
+	markdownLinks := fs.Bool("markdown-links", false, "Write [label](url) Markdown links instead of HTML <a> tags")
+	configPath := fs.String("config", "pssg.yaml", "Path to pssg site config (supplies defaults; content path is written back)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: graph2md [generate] -input graph.json [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Generates a markdown page for every entity in the input graphs.\n\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nRun \"graph2md help\" for the other commands.\n")
+	}
+	fs.Parse(args)
+
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	// Site config supplies defaults for flags not given on the command line.
+	// The default config path is optional; an explicit one must exist.
+	var cfg *siteConfig
+	if *configPath != "" {
+		c, err := loadSiteConfig(*configPath)
+		switch {
+		case err == nil:
+			cfg = c
+			log.Printf("Using site config %s", *configPath)
+		case os.IsNotExist(err) && !setFlags["config"]:
+		default:
+			log.Fatalf("loading config: %v", err)
+		}
+	}
+	if cfg != nil {
+		for _, d := range []struct {
+			flag  string
+			value *string
+			keys  []string
+		}{
+			{"output", outputDir, []string{"paths.content"}},
+			{"repo", repoName, []string{"repo.name"}},
+			{"repo-url", repoURL, []string{"repo.url"}},
+			{"base-path", basePath, []string{"site.base_path"}},
+			{"links", linkStyle, []string{"site.link_style"}},
+			{"layout", layoutName, []string{"site.layout"}},
+			{"source-url-template", sourceTemplate, []string{"repo.source_url_template"}},
+			{"branch", branch, []string{"repo.branch"}},
+		} {
+			if v := cfg.get(d.keys...); v != "" && !setFlags[d.flag] {
+				*d.value = v
+			}
+		}
+	}
+
+	inputs := parseInputs(*inputFiles)
+	if len(inputs) == 0 && cfg != nil {
+		inputs = cfg.repoInputs()
+	}
+	if len(inputs) == 0 {
 		log.Fatal("--input is required (comma-separated paths to graph JSON files)")
 	}
 
-	if err := os.MkdirAll(*outputDir, 0755); err != nil {
-		log.Fatalf("creating output dir: %v", err)
+	links, err := graph2md.ParseLinkStyle(*linkStyle)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	// Load and merge all graphs
-	var allNodes []Node
-	var allRels []Relationship
-	nodeMap := make(map[string]bool)
-
-	for _, path := range strings.Split(*inputFiles, ",") {
-		path = strings.TrimSpace(path)
-		if path == "" {
-			continue
-		}
-		log.Printf("Loading graph from %s...", path)
-		nodes, rels, err := loadGraph(path)
-		if err != nil {
-			log.Printf("Warning: failed to load %s: %v", path, err)
-			continue
-		}
-		for _, n := range nodes {
-			if !nodeMap[n.ID] {
-				nodeMap[n.ID] = true
-				allNodes = append(allNodes, n)
-			}
-		}
-		allRels = append(allRels, rels...)
-		log.Printf("  Loaded %d nodes, %d relationships", len(nodes), len(rels))
+	layout, err := graph2md.ParseLayout(*layoutName)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	log.Printf("Total: %d unique nodes, %d relationships", len(allNodes), len(allRels))
-
-	// Build node lookup: id -> node
-	nodeLookup := make(map[string]*Node)
-	for i := range allNodes {
-		nodeLookup[allNodes[i].ID] = &allNodes[i]
+	mergePolicy, err := graph2md.ParseMergePolicy(*mergeName)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	// Build relationship indices
-	imports := make(map[string][]string)
-	importedBy := make(map[string][]string)
-	callsRel := make(map[string][]string)
-	calledByRel := make(map[string][]string)
-	containsFile := make(map[string][]string)   // directory -> files
-	definesFunc := make(map[string][]string)     // file -> functions
-	declaresClass := make(map[string][]string)   // file -> classes
-	definesType := make(map[string][]string)     // file -> types
-	childDir := make(map[string][]string)        // directory -> subdirectories
-	belongsToDomain := make(map[string]string)   // node -> domain name
-	belongsToSubdomain := make(map[string]string) // node -> subdomain name
-	partOfDomain := make(map[string]string)      // subdomain node ID -> domain name
-	extendsRel := make(map[string][]string)      // class -> parent classes
-
-	// Reverse lookups for "Defined In"
-	fileOfFunc := make(map[string]string)        // function nodeID -> file nodeID
-	fileOfClass := make(map[string]string)       // class nodeID -> file nodeID
-	fileOfType := make(map[string]string)        // type nodeID -> file nodeID
-
-	// Domain/subdomain node lookups by name
-	domainNodeByName := make(map[string]string)    // domain name -> domain node ID
-	subdomainNodeByName := make(map[string]string) // subdomain name -> subdomain node ID
-
-	// Domain -> subdomain mappings
-	domainSubdomains := make(map[string][]string) // domain name -> subdomain node IDs
-
-	// Subdomain -> functions/classes
-	subdomainFuncs := make(map[string][]string)   // subdomain name -> function node IDs
-	subdomainClasses := make(map[string][]string) // subdomain name -> class node IDs
-
-	for _, rel := range allRels {
-		switch rel.Type {
-		case "IMPORTS":
-			imports[rel.StartNode] = append(imports[rel.StartNode], rel.EndNode)
-			importedBy[rel.EndNode] = append(importedBy[rel.EndNode], rel.StartNode)
-		case "calls":
-			callsRel[rel.StartNode] = append(callsRel[rel.StartNode], rel.EndNode)
-			calledByRel[rel.EndNode] = append(calledByRel[rel.EndNode], rel.StartNode)
-		case "CONTAINS_FILE":
-			containsFile[rel.StartNode] = append(containsFile[rel.StartNode], rel.EndNode)
-		case "DEFINES_FUNCTION":
-			definesFunc[rel.StartNode] = append(definesFunc[rel.StartNode], rel.EndNode)
-			fileOfFunc[rel.EndNode] = rel.StartNode
-		case "DECLARES_CLASS":
-			declaresClass[rel.StartNode] = append(declaresClass[rel.StartNode], rel.EndNode)
-			fileOfClass[rel.EndNode] = rel.StartNode
-		case "DEFINES":
-			definesType[rel.StartNode] = append(definesType[rel.StartNode], rel.EndNode)
-			fileOfType[rel.EndNode] = rel.StartNode
-		case "CHILD_DIRECTORY":
-			childDir[rel.StartNode] = append(childDir[rel.StartNode], rel.EndNode)
-		case "EXTENDS":
-			extendsRel[rel.StartNode] = append(extendsRel[rel.StartNode], rel.EndNode)
-		case "belongsTo":
-			endNode := nodeLookup[rel.EndNode]
-			if endNode == nil {
-				continue
-			}
-			name := getStr(endNode.Properties, "name")
-			if hasLabel(endNode, "Domain") {
-				belongsToDomain[rel.StartNode] = name
-			} else if hasLabel(endNode, "Subdomain") {
-				belongsToSubdomain[rel.StartNode] = name
-			}
-		case "partOf":
-			endNode := nodeLookup[rel.EndNode]
-			if endNode != nil {
-				partOfDomain[rel.StartNode] = getStr(endNode.Properties, "name")
-			}
-		}
+	redirects, err := parseRedirectModes(*redirectModes, links, *manifestPath)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	// Build domain/subdomain node-by-name lookups
-	for _, node := range allNodes {
-		if hasLabel(&node, "Domain") {
-			name := getStr(node.Properties, "name")
-			if name != "" {
-				domainNodeByName[name] = node.ID
-			}
-		} else if hasLabel(&node, "Subdomain") {
-			name := getStr(node.Properties, "name")
-			if name != "" {
-				subdomainNodeByName[name] = node.ID
-			}
-		}
+	if *workers < 1 {
+		log.Fatalf("-workers must be at least 1, got %d", *workers)
 	}
-
-	// Build domain -> subdomain mapping from partOf relationships
-	for subNodeID, domName := range partOfDomain {
-		domainSubdomains[domName] = append(domainSubdomains[domName], subNodeID)
+	if *prune != "" && *prune != "delete" && *prune != "archive" {
+		log.Fatalf("unknown -prune mode %q (want delete or archive)", *prune)
 	}
-
-	// Build subdomain -> functions/classes from belongsToSubdomain
-	for nodeID, subName := range belongsToSubdomain {
-		n := nodeLookup[nodeID]
-		if n == nil {
-			continue
-		}
-		if hasLabel(n, "Function") {
-			subdomainFuncs[subName] = append(subdomainFuncs[subName], nodeID)
-		} else if hasLabel(n, "Class") {
-			subdomainClasses[subName] = append(subdomainClasses[subName], nodeID)
+	sourceHost, err := graph2md.ParseSourceHost(*sourceTemplate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *sourceRoot != "" {
+		if fi, err := os.Stat(*sourceRoot); err != nil || !fi.IsDir() {
+			log.Fatalf("--source-root %s is not a directory", *sourceRoot)
 		}
 	}
 
-	// Resolve domain for files via belongsTo on their functions/classes
-	// (files might not have direct belongsTo, but their contents do)
-	// Also check functions belonging to classes declared in the file.
-	for _, node := range allNodes {
-		if !hasLabel(&node, "File") {
-			continue
-		}
-		if _, ok := belongsToDomain[node.ID]; ok {
-			continue
-		}
-		// Check functions in this file
-		for _, fnID := range definesFunc[node.ID] {
-			if d, ok := belongsToDomain[fnID]; ok {
-				belongsToDomain[node.ID] = d
-				break
-			}
-		}
-		if _, ok := belongsToDomain[node.ID]; ok {
-			continue
-		}
-		// Check classes and their methods
-		for _, clsID := range declaresClass[node.ID] {
-			if d, ok := belongsToDomain[clsID]; ok {
-				belongsToDomain[node.ID] = d
-				break
-			}
-			// Check functions defined on this class
-			for _, fnID := range definesFunc[clsID] {
-				if d, ok := belongsToDomain[fnID]; ok {
-					belongsToDomain[node.ID] = d
-					break
-				}
-			}
-			if _, ok := belongsToDomain[node.ID]; ok {
-				break
-			}
-		}
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Fatalf("creating output dir: %v", err)
 	}
 
-	// Similarly resolve subdomain for files
-	for _, node := range allNodes {
-		if !hasLabel(&node, "File") {
-			continue
-		}
-		if _, ok := belongsToSubdomain[node.ID]; ok {
-			continue
-		}
-		for _, fnID := range definesFunc[node.ID] {
-			if s, ok := belongsToSubdomain[fnID]; ok {
-				belongsToSubdomain[node.ID] = s
-				break
+	// Load and merge all graphs
+	merger := graph2md.NewMerger(mergePolicy)
+	var graphCommit, generatedAt string
+	var loaded []*graph2md.GraphResult
+	var failed int   // inputs skipped because they failed to load
+	var exitCode int // of the first unfinished or failed job skipped
+	repos := make(map[string]graph2md.Repo)
+	nodeRepos := make(map[string]string)
+
+	for _, in := range inputs {
+		path := in.path
+		if path == "-" {
+			log.Printf("Loading graph from standard input...")
+		} else {
+			log.Printf("Loading graph from %s...", path)
+		}
+		resp, err := graph2md.LoadResponse(path)
+		if err == nil {
+			if rerr := resp.Err(); rerr != nil {
+				// A job that hasn't finished, or failed without a result,
+				// leaves nothing to render. A failed job may still carry
+				// a partial result, which is used unless -strict is set.
+				code, now := jobExitCode(resp, *strict, len(inputs) == 1)
+				if now {
+					log.Printf("%s: %v", path, rerr)
+					os.Exit(code)
+				}
+				if exitCode == 0 {
+					exitCode = code
+				}
+				if *strict || resp.Result == nil {
+					err = rerr
+				} else {
+					log.Printf("Warning: %s: %v", path, rerr)
+				}
 			}
 		}
-		if _, ok := belongsToSubdomain[node.ID]; ok {
+		if err != nil {
+			if *strict {
+				log.Fatalf("failed to load %s: %v", path, err)
+			}
+			log.Printf("Warning: failed to load %s: %v", path, err)
+			failed++
 			continue
 		}
-		for _, clsID := range declaresClass[node.ID] {
-			if s, ok := belongsToSubdomain[clsID]; ok {
-				belongsToSubdomain[node.ID] = s
-				break
-			}
-			for _, fnID := range definesFunc[clsID] {
-				if s, ok := belongsToSubdomain[fnID]; ok {
-					belongsToSubdomain[node.ID] = s
-					break
+		result := resp.Result
+		loaded = append(loaded, result)
+		if in.repo != "" {
+			repo := graph2md.Repo{Name: in.repo, Branch: *branch, Commit: result.Commit()}
+			if cfg != nil {
+				repo.URL = cfg.get("repos." + in.repo + ".url")
+				repo.Branch = cmp.Or(cfg.get("repos."+in.repo+".branch"), repo.Branch)
+			}
+			if repo.URL == "" {
+				log.Printf("Warning: no URL configured for repo %s (repos.%s.url); its pages get no source links", in.repo, in.repo)
+			}
+			repos[in.repo] = repo
+			for _, n := range result.Graph.Nodes {
+				if _, ok := nodeRepos[n.ID]; !ok {
+					nodeRepos[n.ID] = in.repo
 				}
 			}
-			if _, ok := belongsToSubdomain[node.ID]; ok {
-				break
-			}
-		}
-	}
-
-	// Propagate domain from subdomain's partOf for any node that has a
-	// subdomain but no direct domain assignment.
-	for nodeID, subName := range belongsToSubdomain {
-		if _, ok := belongsToDomain[nodeID]; ok {
-			continue
-		}
-		subNodeID, ok := subdomainNodeByName[subName]
-		if !ok {
-			continue
+		} else if graphCommit == "" {
+			graphCommit = result.Commit()
 		}
-		if domName, ok := partOfDomain[subNodeID]; ok && domName != "" {
-			belongsToDomain[nodeID] = domName
+		if generatedAt == "" {
+			generatedAt = result.GeneratedAt
 		}
+		merger.Add(path, result.Graph)
+		log.Printf("  Loaded %d nodes, %d relationships", len(result.Graph.Nodes), len(result.Graph.Relationships))
 	}
 
-	// Collect all domain members for Domain/Subdomain body sections
-	domainFiles := make(map[string][]string)       // domain name -> file node IDs
-	subdomainFiles := make(map[string][]string)     // subdomain name -> file node IDs
-	for nodeID, domName := range belongsToDomain {
-		n := nodeLookup[nodeID]
-		if n != nil && hasLabel(n, "File") {
-			domainFiles[domName] = append(domainFiles[domName], nodeID)
+	if len(loaded) == 0 {
+		if exitCode != 0 {
+			log.Printf("none of the %d inputs could be loaded", len(inputs))
+			os.Exit(exitCode)
 		}
+		log.Fatalf("none of the %d inputs could be loaded", len(inputs))
 	}
-	for nodeID, subName := range belongsToSubdomain {
-		n := nodeLookup[nodeID]
-		if n != nil && hasLabel(n, "File") {
-			subdomainFiles[subName] = append(subdomainFiles[subName], nodeID)
+
+	merged := merger.Graph()
+	allNodes, allRels := merged.Nodes, merged.Relationships
+	log.Printf("Total: %d unique nodes, %d relationships (peak memory %s)", len(allNodes), len(allRels), peakMemory())
+	reportMerge(merger, *mergeReport)
+	if len(repos) > 0 {
+		var cross int
+		for _, rel := range allRels {
+			if a, b := nodeRepos[rel.StartNode], nodeRepos[rel.EndNode]; a != "" && b != "" && a != b {
+				cross++
+			}
 		}
+		log.Printf("Repos: %d, with %d cross-repo relationships", len(repos), cross)
 	}
 
-	// Which node types to generate pages for
-	generateLabels := map[string]bool{
-		"File": true, "Function": true, "Class": true, "Type": true,
-		"Domain": true, "Subdomain": true, "Directory": true,
+	if *commit == "" && graphCommit != "" {
+		*commit = graphCommit
+		log.Printf("Pinning source links to commit %s from graph metadata", graphCommit)
 	}
 
-	// --- Pass 1: Generate all slugs and build nodeID -> slug lookup ---
-	slugLookup := make(map[string]string)
-	usedSlugs := make(map[string]int)
+	idx := graph2md.BuildIndex(allNodes, allRels)
 
-	type nodeEntry struct {
-		node  Node
-		label string
-		slug  string
+	enrichments, err := graph2md.LoadEnrichments(*enrichmentsDir)
+	if err != nil {
+		log.Fatalf("loading enrichments: %v", err)
+	}
+	if len(enrichments) > 0 {
+		log.Printf("Loaded %d enrichment sidecars from %s", len(enrichments), *enrichmentsDir)
 	}
-	var entries []nodeEntry
 
-	for _, node := range allNodes {
-		if len(node.Labels) == 0 {
-			continue
-		}
-		primaryLabel := node.Labels[0]
-		if !generateLabels[primaryLabel] {
-			continue
-		}
+	// --- Pass 1: Generate all slugs and build nodeID -> slug lookup ---
+	entries, slugLookup := graph2md.AssignRepoSlugs(allNodes, nodeRepos)
 
-		slug := generateSlug(node, primaryLabel)
-		if slug == "" {
-			continue
-		}
+	log.Printf("Pass 1 complete: %d slugs generated", len(entries))
 
-		// Handle slug collisions
-		if n, ok := usedSlugs[slug]; ok {
-			usedSlugs[slug] = n + 1
-			slug = fmt.Sprintf("%s-%d", slug, n+1)
-		} else {
-			usedSlugs[slug] = 1
+	// The previous run's manifest lets unchanged pages be skipped cheaply
+	// and records the slugs pages had before.
+	var prev *graph2md.Manifest
+	if *manifestPath != "" {
+		prev, err = graph2md.ReadManifest(filepath.Join(*outputDir, *manifestPath))
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: ignoring previous manifest: %v", err)
 		}
-
-		slugLookup[node.ID] = slug
-		entries = append(entries, nodeEntry{node: node, label: primaryLabel, slug: slug})
 	}
 
-	log.Printf("Pass 1 complete: %d slugs generated", len(entries))
-
 	// --- Pass 2: Generate markdown with internal links ---
-	var count int
-	for _, e := range entries {
-		ctx := &renderContext{
-			node:               &e.node,
-			label:              e.label,
-			slug:               e.slug,
-			repoName:           *repoName,
-			repoURL:            *repoURL,
-			nodeLookup:         nodeLookup,
-			slugLookup:         slugLookup,
-			imports:            imports,
-			importedBy:         importedBy,
-			calls:              callsRel,
-			calledBy:           calledByRel,
-			containsFile:       containsFile,
-			definesFunc:        definesFunc,
-			declaresClass:      declaresClass,
-			definesType:        definesType,
-			childDir:           childDir,
-			extendsRel:         extendsRel,
-			belongsToDomain:    belongsToDomain,
-			belongsToSubdomain: belongsToSubdomain,
-			partOfDomain:       partOfDomain,
-			domainFiles:        domainFiles,
-			subdomainFiles:     subdomainFiles,
-			fileOfFunc:         fileOfFunc,
-			fileOfClass:        fileOfClass,
-			fileOfType:         fileOfType,
-			domainNodeByName:    domainNodeByName,
-			subdomainNodeByName: subdomainNodeByName,
-			domainSubdomains:   domainSubdomains,
-			subdomainFuncs:     subdomainFuncs,
-			subdomainClasses:   subdomainClasses,
-		}
-
-		md := ctx.generateMarkdown()
-		outPath := filepath.Join(*outputDir, e.slug+".md")
-		if err := os.WriteFile(outPath, []byte(md), 0644); err != nil {
-			log.Printf("Warning: failed to write %s: %v", outPath, err)
-			continue
+	r := &graph2md.Renderer{
+		Index:     idx,
+		Slugs:     slugLookup,
+		Paths:     graph2md.LayoutPaths(layout, entries, idx),
+		RepoName:  *repoName,
+		RepoURL:   *repoURL,
+		Repos:     repos,
+		NodeRepos: nodeRepos,
+		BasePath:  *basePath,
+
+		LinkStyle:        links,
+		MarkdownLinks:    *markdownLinks,
+		Enrichments:      enrichments,
+		SourceHost:       sourceHost,
+		Branch:           *branch,
+		Commit:           *commit,
+		GeneratedAt:      generatedAt,
+		SourceRoot:       *sourceRoot,
+		SnippetLines:     *snippetLines,
+		AliasFrontmatter: redirects["aliases"],
+	}
+	r.Aliases = r.PageHistory(prev, entries)
+
+	if *oldGraphPath != "" {
+		old, err := graph2md.Load(*oldGraphPath)
+		if err != nil {
+			log.Fatalf("loading %s: %v", *oldGraphPath, err)
 		}
-		count++
-	}
-
-	log.Printf("Generated %d entity files in %s", count, *outputDir)
-}
-
-type renderContext struct {
-	node                                          *Node
-	label, slug, repoName, repoURL               string
-	nodeLookup                                    map[string]*Node
-	slugLookup                                    map[string]string
-	imports, importedBy                           map[string][]string
-	calls, calledBy                               map[string][]string
-	containsFile, definesFunc, declaresClass      map[string][]string
-	definesType, childDir, extendsRel             map[string][]string
-	belongsToDomain, belongsToSubdomain           map[string]string
-	partOfDomain                                  map[string]string
-	domainFiles, subdomainFiles                   map[string][]string
-	fileOfFunc, fileOfClass, fileOfType           map[string]string
-	domainNodeByName, subdomainNodeByName         map[string]string
-	domainSubdomains                              map[string][]string
-	subdomainFuncs, subdomainClasses              map[string][]string
-}
-
-// internalLink returns an HTML <a> tag linking to the entity page for nodeID,
-// or plain-text label if no slug is found.
-func (c *renderContext) internalLink(nodeID, label string) string {
-	slug, ok := c.slugLookup[nodeID]
-	if !ok {
-		return html.EscapeString(label)
+		r.Diff = graph2md.DiffGraphs(old, &graph2md.GraphResult{Graph: merged})
+		r.Diff.NewCommit = *commit
+		log.Printf("Diff against %s: %d added, %d removed, %d moved, %d reassigned", *oldGraphPath,
+			len(r.Diff.Added), len(r.Diff.Removed), len(r.Diff.Moved), len(r.Diff.Reassigned))
 	}
-	return fmt.Sprintf(`<a href="/%s.html">%s</a>`, slug, html.EscapeString(label))
-}
 
-// internalLinkByName looks up a domain/subdomain node by name, then links to it.
-func (c *renderContext) domainLink(domainName string) string {
-	nodeID, ok := c.domainNodeByName[domainName]
-	if !ok {
-		return html.EscapeString(domainName)
-	}
-	return c.internalLink(nodeID, domainName)
-}
+	w := graph2md.NewWriter(*outputDir, prev)
+	w.Force = *force
+	manifest := r.NewManifest()
 
-func (c *renderContext) subdomainLink(subdomainName string) string {
-	nodeID, ok := c.subdomainNodeByName[subdomainName]
-	if !ok {
-		return html.EscapeString(subdomainName)
+	pages, errs := renderEntries(r, w, entries, *workers)
+	for _, err := range errs {
+		log.Printf("Warning: %v", err)
 	}
-	return c.internalLink(nodeID, subdomainName)
-}
-
-func (c *renderContext) generateMarkdown() string {
-	var sb strings.Builder
-
-	sb.WriteString("---\n")
-
-	switch c.label {
-	case "File":
-		c.writeFileFrontmatter(&sb)
-	case "Function":
-		c.writeFunctionFrontmatter(&sb)
-	case "Class":
-		c.writeClassFrontmatter(&sb)
-	case "Type":
-		c.writeTypeFrontmatter(&sb)
-	case "Domain":
-		c.writeDomainFrontmatter(&sb)
-	case "Subdomain":
-		c.writeSubdomainFrontmatter(&sb)
-	case "Directory":
-		c.writeDirectoryFrontmatter(&sb)
+	writeErrs := len(errs)
+	if len(errs) > 0 {
+		log.Printf("Warning: failed to write %d of %d entity files", len(errs), len(entries))
 	}
+	manifest.Pages = append(manifest.Pages, pages...)
 
-	// Write graph_data, mermaid_diagram, arch_map frontmatter fields
-	c.writeGraphData(&sb)
-	c.writeMermaidDiagram(&sb)
-	c.writeArchMap(&sb)
-
-	sb.WriteString("---\n\n")
+	log.Printf("Generated %d entity files in %s", len(pages), *outputDir)
 
-	switch c.label {
-	case "File":
-		c.writeFileBody(&sb)
-	case "Function":
-		c.writeFunctionBody(&sb)
-	case "Class":
-		c.writeClassBody(&sb)
-	case "Type":
-		c.writeTypeBody(&sb)
-	case "Domain":
-		c.writeDomainBody(&sb)
-	case "Subdomain":
-		c.writeSubdomainBody(&sb)
-	case "Directory":
-		c.writeDirectoryBody(&sb)
+	if *indexPages {
+		// Reported stats describe a single graph; recount for merged input.
+		stats := graph2md.ComputeStats(allNodes, allRels)
+		if len(loaded) == 1 && loaded[0].Stats.NodeCount > 0 {
+			stats = loaded[0].Stats
+		}
+		pages := r.RenderIndexPages(entries, stats)
+		for _, p := range pages {
+			out := r.PagePath(p.Slug) + ".md"
+			if err := w.Write(out, p.Content); err != nil {
+				log.Printf("Warning: failed to write %s: %v", out, err)
+				writeErrs++
+				continue
+			}
+			manifest.Pages = append(manifest.Pages, r.IndexPage(p, out))
+		}
+		log.Printf("Generated %d index pages", len(pages))
 	}
 
-	// FAQ section at the end of body
-	c.writeFAQSection(&sb)
-
-	return sb.String()
-}
-
-// --- Frontmatter writers ---
-
-func (c *renderContext) writeFileFrontmatter(sb *strings.Builder) {
-	props := c.node.Properties
-	path := getStr(props, "path")
-	name := getStr(props, "name")
-	lang := getStr(props, "language")
-	if name == "" {
-		name = filepath.Base(path)
+	if r.Diff != nil {
+		p := r.RenderChangelog()
+		if err := w.Write(p.Slug+".md", p.Content); err != nil {
+			log.Printf("Warning: failed to write %s: %v", p.Slug+".md", err)
+			writeErrs++
+		} else {
+			manifest.Pages = append(manifest.Pages, r.IndexPage(p, p.Slug+".md"))
+		}
 	}
 
-	title := fmt.Sprintf("%s — %s Source File", name, c.repoName)
-	desc := fmt.Sprintf("Architecture documentation for %s", name)
-	if lang != "" {
-		desc += fmt.Sprintf(", a %s file", lang)
+	if redirects["html"] || redirects["netlify"] {
+		writeErrs += writeRedirects(r, entries, *redirectsDir, *outputDir, redirects)
 	}
-	desc += fmt.Sprintf(" in the %s codebase.", c.repoName)
 
-	depCount := len(c.imports[c.node.ID])
-	ibCount := len(c.importedBy[c.node.ID])
-	if depCount > 0 || ibCount > 0 {
-		desc += fmt.Sprintf(" %d imports, %d dependents.", depCount, ibCount)
-	}
+	stats := w.Stats()
+	stats.Removed = len(manifest.Missing(prev))
 
-	sb.WriteString(fmt.Sprintf("title: %q\n", title))
-	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
-	sb.WriteString("node_type: \"File\"\n")
-	sb.WriteString(fmt.Sprintf("file_path: %q\n", path))
-	sb.WriteString(fmt.Sprintf("file_name: %q\n", name))
-	if lang != "" {
-		sb.WriteString(fmt.Sprintf("language: %q\n", lang))
+	// Pages generated earlier but gone from the graph are pruned on
+	// request; otherwise they stay on record so a later prune finds them.
+	stale, modified, err := graph2md.StalePages(*outputDir, manifest, prev, *archiveDir)
+	if err != nil {
+		log.Fatalf("finding stale pages: %v", err)
 	}
-	sb.WriteString(fmt.Sprintf("repo: %q\n", c.repoName))
-	sb.WriteString(fmt.Sprintf("repo_url: %q\n", c.repoURL))
-
-	dir := filepath.Dir(path)
-	if dir != "" && dir != "." {
-		sb.WriteString(fmt.Sprintf("directory: %q\n", dir))
-		parts := strings.Split(dir, "/")
-		if len(parts) > 0 {
-			sb.WriteString(fmt.Sprintf("top_directory: %q\n", parts[0]))
-		}
+	for _, p := range modified {
+		log.Printf("  Keeping %s: modified since it was generated", p.OutputPath)
 	}
-
-	ext := filepath.Ext(name)
-	if ext != "" {
-		sb.WriteString(fmt.Sprintf("extension: %q\n", ext))
+	manifest.Stale = modified
+	// Pages of an input that failed to load look stale but aren't.
+	if failed > 0 && *prune != "" {
+		log.Printf("Warning: not pruning because %d inputs failed to load", failed)
+		*prune = ""
 	}
-
-	if d, ok := c.belongsToDomain[c.node.ID]; ok {
-		sb.WriteString(fmt.Sprintf("domain: %q\n", d))
+	if *prune != "" || *pruneDryRun {
+		for _, p := range stale {
+			log.Printf("  Stale: %s", p.OutputPath)
+		}
 	}
-	if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-		sb.WriteString(fmt.Sprintf("subdomain: %q\n", s))
+	switch {
+	case len(stale) == 0:
+	case *pruneDryRun || *prune == "":
+		manifest.Stale = append(manifest.Stale, stale...)
+		if *pruneDryRun {
+			log.Printf("Dry run: %d stale pages would be pruned", len(stale))
+		} else {
+			log.Printf("%d stale pages left in place (use -prune to remove them)", len(stale))
+		}
+	case *prune == "archive":
+		if err := graph2md.PrunePages(*outputDir, stale, *archiveDir); err != nil {
+			log.Fatalf("archiving stale pages: %v", err)
+		}
+		log.Printf("Archived %d stale pages to %s", len(stale), *archiveDir)
+	default:
+		if err := graph2md.PrunePages(*outputDir, stale, ""); err != nil {
+			log.Fatalf("pruning stale pages: %v", err)
+		}
+		log.Printf("Pruned %d stale pages", len(stale))
 	}
 
-	sb.WriteString(fmt.Sprintf("import_count: %d\n", depCount))
-	sb.WriteString(fmt.Sprintf("imported_by_count: %d\n", ibCount))
-
-	funcCount := len(c.definesFunc[c.node.ID])
-	classCount := len(c.declaresClass[c.node.ID])
-	typeCount := len(c.definesType[c.node.ID])
-	sb.WriteString(fmt.Sprintf("function_count: %d\n", funcCount))
-	sb.WriteString(fmt.Sprintf("class_count: %d\n", classCount))
-	sb.WriteString(fmt.Sprintf("type_count: %d\n", typeCount))
-
-	c.writeTags(sb)
-}
-
-func (c *renderContext) writeFunctionFrontmatter(sb *strings.Builder) {
-	props := c.node.Properties
-	name := getStr(props, "name")
-	filePath := getStr(props, "filePath")
-	lang := getStr(props, "language")
-	startLine := getNum(props, "startLine")
-	endLine := getNum(props, "endLine")
+	log.Printf("Pages: %s", stats)
+	log.Printf("Peak memory: %s", peakMemory())
 
-	title := fmt.Sprintf("%s() — %s Function Reference", name, c.repoName)
-	desc := fmt.Sprintf("Architecture documentation for the %s() function", name)
-	if filePath != "" {
-		desc += fmt.Sprintf(" in %s", filepath.Base(filePath))
+	if *manifestPath != "" {
+		path := filepath.Join(*outputDir, *manifestPath)
+		if err := manifest.Write(path); err != nil {
+			log.Fatalf("writing manifest: %v", err)
+		}
+		log.Printf("Wrote manifest of %d pages to %s", len(manifest.Pages), path)
 	}
-	desc += fmt.Sprintf(" from the %s codebase.", c.repoName)
 
-	sb.WriteString(fmt.Sprintf("title: %q\n", title))
-	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
-	sb.WriteString("node_type: \"Function\"\n")
-	sb.WriteString(fmt.Sprintf("function_name: %q\n", name))
-	if filePath != "" {
-		sb.WriteString(fmt.Sprintf("file_path: %q\n", filePath))
-		dir := filepath.Dir(filePath)
-		if dir != "" && dir != "." {
-			sb.WriteString(fmt.Sprintf("directory: %q\n", dir))
+	if cfg != nil && cfg.get("paths.content") != *outputDir {
+		cfg.set("paths.content", *outputDir)
+		if err := cfg.save(); err != nil {
+			log.Fatalf("updating config: %v", err)
 		}
+		log.Printf("Updated content path in %s", *configPath)
 	}
-	if lang != "" {
-		sb.WriteString(fmt.Sprintf("language: %q\n", lang))
-	}
-	if startLine > 0 {
-		sb.WriteString(fmt.Sprintf("start_line: %d\n", startLine))
-	}
-	if endLine > 0 {
-		sb.WriteString(fmt.Sprintf("end_line: %d\n", endLine))
-		sb.WriteString(fmt.Sprintf("line_count: %d\n", endLine-startLine+1))
-	}
-	sb.WriteString(fmt.Sprintf("repo: %q\n", c.repoName))
-	sb.WriteString(fmt.Sprintf("call_count: %d\n", len(c.calls[c.node.ID])))
-	sb.WriteString(fmt.Sprintf("called_by_count: %d\n", len(c.calledBy[c.node.ID])))
 
-	if d, ok := c.belongsToDomain[c.node.ID]; ok {
-		sb.WriteString(fmt.Sprintf("domain: %q\n", d))
+	if *strict && writeErrs > 0 {
+		log.Fatalf("-strict: %d writes failed", writeErrs)
 	}
-	if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-		sb.WriteString(fmt.Sprintf("subdomain: %q\n", s))
+	if exitCode != 0 {
+		log.Printf("Skipped %d inputs; exiting with %d for the first unfinished or failed job", failed, exitCode)
+		os.Exit(exitCode)
 	}
-
-	c.writeTags(sb)
 }
 
-func (c *renderContext) writeClassFrontmatter(sb *strings.Builder) {
-	props := c.node.Properties
-	name := getStr(props, "name")
-	filePath := getStr(props, "filePath")
-	lang := getStr(props, "language")
-	startLine := getNum(props, "startLine")
-	endLine := getNum(props, "endLine")
-
-	title := fmt.Sprintf("%s Class — %s Architecture", name, c.repoName)
-	desc := fmt.Sprintf("Architecture documentation for the %s class", name)
-	if filePath != "" {
-		desc += fmt.Sprintf(" in %s", filepath.Base(filePath))
-	}
-	desc += fmt.Sprintf(" from the %s codebase.", c.repoName)
-
-	sb.WriteString(fmt.Sprintf("title: %q\n", title))
-	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
-	sb.WriteString("node_type: \"Class\"\n")
-	sb.WriteString(fmt.Sprintf("class_name: %q\n", name))
-	if filePath != "" {
-		sb.WriteString(fmt.Sprintf("file_path: %q\n", filePath))
-		dir := filepath.Dir(filePath)
-		if dir != "" && dir != "." {
-			sb.WriteString(fmt.Sprintf("directory: %q\n", dir))
+// parseRedirectModes parses the comma-separated -redirects list and checks
+// that the other flags allow it: redirects are found from the previous
+// run's manifest, and they need site URLs, which -links md doesn't have.
+func parseRedirectModes(list string, links graph2md.LinkStyle, manifestPath string) (map[string]bool, error) {
+	redirects := make(map[string]bool)
+	for _, m := range strings.Split(list, ",") {
+		switch m = strings.TrimSpace(m); m {
+		case "":
+		case "aliases", "html", "netlify":
+			redirects[m] = true
+		default:
+			return nil, fmt.Errorf("unknown -redirects mode %q (want aliases, html or netlify)", m)
 		}
 	}
-	if lang != "" {
-		sb.WriteString(fmt.Sprintf("language: %q\n", lang))
-	}
-	if startLine > 0 {
-		sb.WriteString(fmt.Sprintf("start_line: %d\n", startLine))
-	}
-	if endLine > 0 {
-		sb.WriteString(fmt.Sprintf("end_line: %d\n", endLine))
-		sb.WriteString(fmt.Sprintf("line_count: %d\n", endLine-startLine+1))
+	if len(redirects) == 0 {
+		return redirects, nil
 	}
-	sb.WriteString(fmt.Sprintf("repo: %q\n", c.repoName))
-
-	if d, ok := c.belongsToDomain[c.node.ID]; ok {
-		sb.WriteString(fmt.Sprintf("domain: %q\n", d))
-	}
-	if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-		sb.WriteString(fmt.Sprintf("subdomain: %q\n", s))
+	if links == graph2md.LinkMarkdown {
+		return nil, fmt.Errorf("-redirects needs site URLs and can't be used with -links md")
 	}
-
-	extends := c.extendsRel[c.node.ID]
-	if len(extends) > 0 {
-		names := c.resolveNames(extends)
-		sb.WriteString(fmt.Sprintf("extends: %q\n", strings.Join(names, ", ")))
+	if manifestPath == "" {
+		return nil, fmt.Errorf("-redirects finds renamed pages in the previous manifest and can't be used with -manifest \"\"")
 	}
-
-	c.writeTags(sb)
+	return redirects, nil
 }
 
-func (c *renderContext) writeTypeFrontmatter(sb *strings.Builder) {
-	props := c.node.Properties
-	name := getStr(props, "name")
-	filePath := getStr(props, "filePath")
-	lang := getStr(props, "language")
-	startLine := getNum(props, "startLine")
-	endLine := getNum(props, "endLine")
+// input is a graph to load, optionally tagged with the repository it
+// documents.
+type input struct {
+	repo, path string
+}
 
-	title := fmt.Sprintf("%s Type — %s Architecture", name, c.repoName)
-	desc := fmt.Sprintf("Architecture documentation for the %s type/interface", name)
-	if filePath != "" {
-		desc += fmt.Sprintf(" in %s", filepath.Base(filePath))
-	}
-	desc += fmt.Sprintf(" from the %s codebase.", c.repoName)
+// repoTag matches the repo names that may prefix an -input path. Anything
+// else before an "=", such as a directory, is part of the path.
+var repoTag = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
 
-	sb.WriteString(fmt.Sprintf("title: %q\n", title))
-	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
-	sb.WriteString("node_type: \"Type\"\n")
-	sb.WriteString(fmt.Sprintf("type_name: %q\n", name))
-	if filePath != "" {
-		sb.WriteString(fmt.Sprintf("file_path: %q\n", filePath))
-		dir := filepath.Dir(filePath)
-		if dir != "" && dir != "." {
-			sb.WriteString(fmt.Sprintf("directory: %q\n", dir))
+// parseInputs parses a comma-separated -input list, where each item is a
+// path or repo=path.
+func parseInputs(list string) []input {
+	var inputs []input
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
 		}
+		in := input{path: item}
+		if repo, path, ok := strings.Cut(item, "="); ok && repoTag.MatchString(strings.TrimSpace(repo)) {
+			in = input{repo: strings.TrimSpace(repo), path: strings.TrimSpace(path)}
+		}
+		inputs = append(inputs, in)
 	}
-	if lang != "" {
-		sb.WriteString(fmt.Sprintf("language: %q\n", lang))
-	}
-	if startLine > 0 {
-		sb.WriteString(fmt.Sprintf("start_line: %d\n", startLine))
-	}
-	if endLine > 0 {
-		sb.WriteString(fmt.Sprintf("end_line: %d\n", endLine))
-		sb.WriteString(fmt.Sprintf("line_count: %d\n", endLine-startLine+1))
-	}
-	sb.WriteString(fmt.Sprintf("repo: %q\n", c.repoName))
-
-	if d, ok := c.belongsToDomain[c.node.ID]; ok {
-		sb.WriteString(fmt.Sprintf("domain: %q\n", d))
-	}
-	if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-		sb.WriteString(fmt.Sprintf("subdomain: %q\n", s))
-	}
-
-	c.writeTags(sb)
+	return inputs
 }
 
-func (c *renderContext) writeDomainFrontmatter(sb *strings.Builder) {
-	name := getStr(c.node.Properties, "name")
-	if name == "" {
-		name = c.node.ID
-	}
+// maxConflictsLogged caps the merge conflicts listed in the log; the
+// -merge-report file has all of them.
+const maxConflictsLogged = 20
 
-	nodeDesc := getStr(c.node.Properties, "description")
-	fileCount := len(c.domainFiles[name])
-	title := fmt.Sprintf("%s Domain — %s Architecture", name, c.repoName)
-	desc := ""
-	if nodeDesc != "" {
-		desc = nodeDesc + " "
+// loadGraphs loads the comma-separated -input list of a read-only command,
+// exiting if a graph fails to load. It returns each input's result, and the
+// graphs merged and deduplicated as generate does, keeping the first copy of
+// a node found in several inputs. nodeRepos maps the nodes of inputs tagged
+// name=path to their repository.
+func loadGraphs(list string) (results []*graph2md.GraphResult, g graph2md.Graph, nodeRepos map[string]string) {
+	inputs := parseInputs(list)
+	if len(inputs) == 0 {
+		log.Fatal("-input is required (comma-separated paths to graph JSON files)")
 	}
-	desc += fmt.Sprintf("Architectural overview of the %s domain in the %s codebase. Contains %d source files.", name, c.repoName, fileCount)
-
-	sb.WriteString(fmt.Sprintf("title: %q\n", title))
-	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
-	sb.WriteString("node_type: \"Domain\"\n")
-	sb.WriteString(fmt.Sprintf("domain: %q\n", name))
-	sb.WriteString(fmt.Sprintf("repo: %q\n", c.repoName))
-	sb.WriteString(fmt.Sprintf("file_count: %d\n", fileCount))
-	if nodeDesc != "" {
-		sb.WriteString(fmt.Sprintf("summary: %q\n", nodeDesc))
+	merger := graph2md.NewMerger(graph2md.MergeFirst)
+	nodeRepos = make(map[string]string)
+	for _, in := range inputs {
+		result, err := graph2md.Load(in.path)
+		if err != nil {
+			log.Fatalf("loading %s: %v", in.path, err)
+		}
+		results = append(results, result)
+		if in.repo != "" {
+			for _, n := range result.Graph.Nodes {
+				if _, ok := nodeRepos[n.ID]; !ok {
+					nodeRepos[n.ID] = in.repo
+				}
+			}
+		}
+		merger.Add(in.path, result.Graph)
 	}
-
-	c.writeTags(sb)
+	return results, merger.Graph(), nodeRepos
 }
 
-func (c *renderContext) writeSubdomainFrontmatter(sb *strings.Builder) {
-	name := getStr(c.node.Properties, "name")
-	if name == "" {
-		name = c.node.ID
-	}
-
-	nodeDesc := getStr(c.node.Properties, "description")
-	parentDomain := c.partOfDomain[c.node.ID]
-	fileCount := len(c.subdomainFiles[name])
-
-	title := fmt.Sprintf("%s — %s Architecture", name, c.repoName)
-	desc := ""
-	if nodeDesc != "" {
-		desc = nodeDesc + " "
+// reportMerge logs the relationships dropped and node property conflicts
+// found while merging inputs, and writes the conflicts to reportPath if set.
+func reportMerge(m *graph2md.Merger, reportPath string) {
+	if n := m.DuplicateRelationships(); n > 0 {
+		log.Printf("Merge: dropped %d duplicate relationships", n)
 	}
-	desc += fmt.Sprintf("Architecture documentation for the %s subdomain", name)
-	if parentDomain != "" {
-		desc += fmt.Sprintf(" (part of %s domain)", parentDomain)
-	}
-	desc += fmt.Sprintf(" in the %s codebase. Contains %d source files.", c.repoName, fileCount)
-
-	sb.WriteString(fmt.Sprintf("title: %q\n", title))
-	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
-	sb.WriteString("node_type: \"Subdomain\"\n")
-	sb.WriteString(fmt.Sprintf("subdomain: %q\n", name))
-	if parentDomain != "" {
-		sb.WriteString(fmt.Sprintf("domain: %q\n", parentDomain))
+	if n := m.RelationshipIDCollisions(); n > 0 {
+		log.Printf("Warning: merge: kept %d relationships whose IDs were already used by a different relationship", n)
 	}
-	sb.WriteString(fmt.Sprintf("repo: %q\n", c.repoName))
-	sb.WriteString(fmt.Sprintf("file_count: %d\n", fileCount))
-	if nodeDesc != "" {
-		sb.WriteString(fmt.Sprintf("summary: %q\n", nodeDesc))
+	conflicts := m.Conflicts()
+	if len(conflicts) > 0 {
+		log.Printf("Merge: %d conflicting node properties between inputs (-merge %s)", len(conflicts), m.Policy)
+		for i, c := range conflicts {
+			if i == maxConflictsLogged {
+				log.Printf("  ... and %d more", len(conflicts)-i)
+				break
+			}
+			log.Printf("  %s", c)
+		}
 	}
-
-	c.writeTags(sb)
-}
-
-func (c *renderContext) writeDirectoryFrontmatter(sb *strings.Builder) {
-	props := c.node.Properties
-	name := getStr(props, "name")
-	path := getStr(props, "path")
-	if name == "" {
-		name = filepath.Base(path)
+	if reportPath == "" {
+		return
 	}
-	if path == "" {
-		path = name
+	if conflicts == nil {
+		conflicts = []graph2md.Conflict{}
 	}
-
-	// Skip root directory
-	if strings.Contains(path, "/app/repo-root/") {
-		return
+	data, err := json.MarshalIndent(conflicts, "", "  ")
+	if err == nil {
+		err = os.WriteFile(reportPath, append(data, '\n'), 0644)
 	}
-
-	fileCount := len(c.containsFile[c.node.ID])
-	subdirCount := len(c.childDir[c.node.ID])
-
-	title := fmt.Sprintf("%s/ — %s Directory Structure", path, c.repoName)
-	desc := fmt.Sprintf("Directory listing for %s/ in the %s codebase. Contains %d files and %d subdirectories.", path, c.repoName, fileCount, subdirCount)
-
-	sb.WriteString(fmt.Sprintf("title: %q\n", title))
-	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
-	sb.WriteString("node_type: \"Directory\"\n")
-	sb.WriteString(fmt.Sprintf("dir_name: %q\n", name))
-	sb.WriteString(fmt.Sprintf("dir_path: %q\n", path))
-	sb.WriteString(fmt.Sprintf("repo: %q\n", c.repoName))
-	sb.WriteString(fmt.Sprintf("file_count: %d\n", fileCount))
-	sb.WriteString(fmt.Sprintf("subdir_count: %d\n", subdirCount))
-
-	parts := strings.Split(path, "/")
-	if len(parts) > 0 {
-		sb.WriteString(fmt.Sprintf("top_directory: %q\n", parts[0]))
+	if err != nil {
+		log.Fatalf("writing merge report: %v", err)
 	}
-
-	c.writeTags(sb)
+	log.Printf("Wrote merge report to %s", reportPath)
 }
 
-// --- Body writers ---
-
-func (c *renderContext) writeFileBody(sb *strings.Builder) {
-	props := c.node.Properties
-	path := getStr(props, "path")
-
-	// Domain link
-	if d, ok := c.belongsToDomain[c.node.ID]; ok {
-		sb.WriteString("## Domain\n\n")
-		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(d)))
-		sb.WriteString("\n")
-
-		// Subdomain link (only show if domain exists)
-		if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-			sb.WriteString("## Subdomains\n\n")
-			sb.WriteString(fmt.Sprintf("- %s\n", c.subdomainLink(s)))
-			sb.WriteString("\n")
+// peakMemory reports the process's peak resident memory, or the memory
+// obtained from the OS by the Go runtime where that isn't available.
+func peakMemory() string {
+	if data, err := os.ReadFile("/proc/self/status"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if v, ok := strings.CutPrefix(line, "VmHWM:"); ok {
+				if kb, err := strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v), "kB")), 10, 64); err == nil {
+					return formatBytes(kb << 10)
+				}
+			}
 		}
 	}
-
-	// Functions defined in this file
-	funcs := c.definesFunc[c.node.ID]
-	if len(funcs) > 0 {
-		sb.WriteString("## Functions\n\n")
-		c.writeLinkedList(sb, funcs, func(id string) string {
-			name := c.resolveName(id)
-			return c.internalLink(id, name+"()")
-		})
-	}
-
-	// Classes defined in this file
-	classes := c.declaresClass[c.node.ID]
-	if len(classes) > 0 {
-		sb.WriteString("## Classes\n\n")
-		c.writeLinkedList(sb, classes, func(id string) string {
-			return c.internalLink(id, c.resolveName(id))
-		})
-	}
-
-	// Types defined in this file
-	types := c.definesType[c.node.ID]
-	if len(types) > 0 {
-		sb.WriteString("## Types\n\n")
-		c.writeLinkedList(sb, types, func(id string) string {
-			return c.internalLink(id, c.resolveName(id))
-		})
-	}
-
-	// Dependencies
-	deps := c.imports[c.node.ID]
-	if len(deps) > 0 {
-		sb.WriteString("## Dependencies\n\n")
-		c.writeLinkedList(sb, deps, func(id string) string {
-			return c.internalLink(id, c.resolveName(id))
-		})
-	}
-
-	// Imported By
-	ib := c.importedBy[c.node.ID]
-	if len(ib) > 0 {
-		sb.WriteString("## Imported By\n\n")
-		c.writeLinkedList(sb, ib, func(id string) string {
-			return c.internalLink(id, c.resolveNameWithPath(id))
-		})
-	}
-
-	// Source link
-	if path != "" && c.repoURL != "" {
-		sb.WriteString("## Source\n\n")
-		sb.WriteString(fmt.Sprintf("- <a href=\"%s/blob/main/%s\">View on GitHub</a>\n\n", c.repoURL, path))
-	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return formatBytes(int64(m.Sys))
 }
 
-func (c *renderContext) writeFunctionBody(sb *strings.Builder) {
-	props := c.node.Properties
-	filePath := getStr(props, "filePath")
-	startLine := getNum(props, "startLine")
-
-	// Defined In
-	if fileID, ok := c.fileOfFunc[c.node.ID]; ok {
-		sb.WriteString("## Defined In\n\n")
-		sb.WriteString(fmt.Sprintf("- %s\n", c.internalLink(fileID, c.resolveNameWithPath(fileID))))
-		sb.WriteString("\n")
-	}
-
-	// Domain link
-	if d, ok := c.belongsToDomain[c.node.ID]; ok {
-		sb.WriteString("## Domain\n\n")
-		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(d)))
-		sb.WriteString("\n")
+func formatBytes(n int64) string {
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
 
-		if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-			sb.WriteString("## Subdomains\n\n")
-			sb.WriteString(fmt.Sprintf("- %s\n", c.subdomainLink(s)))
-			sb.WriteString("\n")
-		}
-	}
+// renderEntries renders and writes the entity pages on up to workers
+// goroutines. Pages and errors are returned in entry order, regardless of
+// which goroutine finished first, so the manifest and log are deterministic.
+func renderEntries(r *graph2md.Renderer, w *graph2md.Writer, entries []graph2md.Entry, workers int) ([]graph2md.ManifestPage, []error) {
+	pages := make([]graph2md.ManifestPage, len(entries))
+	errs := make([]error, len(entries))
 
-	// Calls
-	called := c.calls[c.node.ID]
-	if len(called) > 0 {
-		sb.WriteString("## Calls\n\n")
-		c.writeLinkedList(sb, called, func(id string) string {
-			name := c.resolveName(id)
-			return c.internalLink(id, name+"()")
-		})
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(entries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				e := entries[i]
+				md := r.RenderEntry(e)
+				out := r.PagePath(e.Slug) + ".md"
+				if err := w.Write(out, md); err != nil {
+					errs[i] = fmt.Errorf("failed to write %s: %w", out, err)
+					continue
+				}
+				pages[i] = r.EntryPage(e, md, out)
+			}
+		}()
 	}
-
-	// Called By
-	callers := c.calledBy[c.node.ID]
-	if len(callers) > 0 {
-		sb.WriteString("## Called By\n\n")
-		c.writeLinkedList(sb, callers, func(id string) string {
-			name := c.resolveName(id)
-			return c.internalLink(id, name+"()")
-		})
+	for i := range entries {
+		next <- i
 	}
+	close(next)
+	wg.Wait()
 
-	// Source
-	if filePath != "" && c.repoURL != "" {
-		sb.WriteString("## Source\n\n")
-		link := fmt.Sprintf("%s/blob/main/%s", c.repoURL, filePath)
-		if startLine > 0 {
-			link += fmt.Sprintf("#L%d", startLine)
+	var written []graph2md.ManifestPage
+	var failed []error
+	for i := range entries {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+		} else {
+			written = append(written, pages[i])
 		}
-		sb.WriteString(fmt.Sprintf("- <a href=\"%s\">View on GitHub</a>\n\n", link))
 	}
+	return written, failed
 }
 
-func (c *renderContext) writeClassBody(sb *strings.Builder) {
-	props := c.node.Properties
-	filePath := getStr(props, "filePath")
-	startLine := getNum(props, "startLine")
-
-	// Defined In
-	if fileID, ok := c.fileOfClass[c.node.ID]; ok {
-		sb.WriteString("## Defined In\n\n")
-		sb.WriteString(fmt.Sprintf("- %s\n", c.internalLink(fileID, c.resolveNameWithPath(fileID))))
-		sb.WriteString("\n")
-	}
-
-	// Domain link
-	if d, ok := c.belongsToDomain[c.node.ID]; ok {
-		sb.WriteString("## Domain\n\n")
-		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(d)))
-		sb.WriteString("\n")
-
-		if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-			sb.WriteString("## Subdomains\n\n")
-			sb.WriteString(fmt.Sprintf("- %s\n", c.subdomainLink(s)))
-			sb.WriteString("\n")
-		}
+// writeRedirects writes HTML redirect stubs and/or a Netlify _redirects file
+// for every page whose URL changed since an earlier run, and returns the
+// number of files that failed to write.
+func writeRedirects(r *graph2md.Renderer, entries []graph2md.Entry, dir, outputDir string, modes map[string]bool) int {
+	if dir == "" {
+		dir = outputDir
 	}
-
-	// Extends
-	extends := c.extendsRel[c.node.ID]
-	if len(extends) > 0 {
-		sb.WriteString("## Extends\n\n")
-		for _, id := range extends {
-			sb.WriteString(fmt.Sprintf("- %s\n", c.internalLink(id, c.resolveName(id))))
+	rw := graph2md.NewWriter(dir, nil)
+	list := r.Redirects(entries)
+	var failed int
+	if modes["html"] {
+		for _, rd := range list {
+			if err := rw.Write(r.StubPath(rd), graph2md.RedirectStub(rd.To)); err != nil {
+				log.Printf("Warning: failed to write redirect stub for %s: %v", rd.From, err)
+				failed++
+			}
 		}
-		sb.WriteString("\n")
 	}
-
-	// Source
-	if filePath != "" && c.repoURL != "" {
-		sb.WriteString("## Source\n\n")
-		link := fmt.Sprintf("%s/blob/main/%s", c.repoURL, filePath)
-		if startLine > 0 {
-			link += fmt.Sprintf("#L%d", startLine)
+	if modes["netlify"] {
+		if err := rw.Write("_redirects", graph2md.NetlifyRedirects(list)); err != nil {
+			log.Printf("Warning: failed to write _redirects: %v", err)
+			failed++
 		}
-		sb.WriteString(fmt.Sprintf("- <a href=\"%s\">View on GitHub</a>\n\n", link))
-	}
-}
-
-func (c *renderContext) writeTypeBody(sb *strings.Builder) {
-	props := c.node.Properties
-	filePath := getStr(props, "filePath")
-	startLine := getNum(props, "startLine")
-
-	// Defined In
-	if fileID, ok := c.fileOfType[c.node.ID]; ok {
-		sb.WriteString("## Defined In\n\n")
-		sb.WriteString(fmt.Sprintf("- %s\n", c.internalLink(fileID, c.resolveNameWithPath(fileID))))
-		sb.WriteString("\n")
-	}
-
-	// Domain link
-	if d, ok := c.belongsToDomain[c.node.ID]; ok {
-		sb.WriteString("## Domain\n\n")
-		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(d)))
-		sb.WriteString("\n")
-
-		if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-			sb.WriteString("## Subdomains\n\n")
-			sb.WriteString(fmt.Sprintf("- %s\n", c.subdomainLink(s)))
-			sb.WriteString("\n")
-		}
-	}
-
-	if filePath != "" && c.repoURL != "" {
-		sb.WriteString("## Source\n\n")
-		link := fmt.Sprintf("%s/blob/main/%s", c.repoURL, filePath)
-		if startLine > 0 {
-			link += fmt.Sprintf("#L%d", startLine)
-		}
-		sb.WriteString(fmt.Sprintf("- <a href=\"%s\">View on GitHub</a>\n\n", link))
-	}
-}
-
-func (c *renderContext) writeDomainBody(sb *strings.Builder) {
-	name := getStr(c.node.Properties, "name")
-
-	// Subdomains
-	subs := c.domainSubdomains[name]
-	if len(subs) > 0 {
-		sb.WriteString("## Subdomains\n\n")
-		c.writeLinkedList(sb, subs, func(id string) string {
-			return c.internalLink(id, c.resolveName(id))
-		})
-	}
-
-	// Source Files
-	files := c.domainFiles[name]
-	if len(files) > 0 {
-		sb.WriteString("## Source Files\n\n")
-		c.writeLinkedList(sb, files, func(id string) string {
-			return c.internalLink(id, c.resolveNameWithPath(id))
-		})
-	}
-}
-
-func (c *renderContext) writeSubdomainBody(sb *strings.Builder) {
-	name := getStr(c.node.Properties, "name")
-
-	// Domain link
-	if parentDomain := c.partOfDomain[c.node.ID]; parentDomain != "" {
-		sb.WriteString("## Domain\n\n")
-		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(parentDomain)))
-		sb.WriteString("\n")
-	}
-
-	// Functions in this subdomain
-	funcs := c.subdomainFuncs[name]
-	if len(funcs) > 0 {
-		sb.WriteString("## Functions\n\n")
-		c.writeLinkedList(sb, funcs, func(id string) string {
-			fnName := c.resolveName(id)
-			return c.internalLink(id, fnName+"()")
-		})
-	}
-
-	// Classes in this subdomain
-	classes := c.subdomainClasses[name]
-	if len(classes) > 0 {
-		sb.WriteString("## Classes\n\n")
-		c.writeLinkedList(sb, classes, func(id string) string {
-			return c.internalLink(id, c.resolveName(id))
-		})
-	}
-
-	// Source Files
-	files := c.subdomainFiles[name]
-	if len(files) > 0 {
-		sb.WriteString("## Source Files\n\n")
-		c.writeLinkedList(sb, files, func(id string) string {
-			return c.internalLink(id, c.resolveNameWithPath(id))
-		})
-	}
-}
-
-func (c *renderContext) writeDirectoryBody(sb *strings.Builder) {
-	// Subdirectories
-	subdirs := c.childDir[c.node.ID]
-	if len(subdirs) > 0 {
-		sb.WriteString("## Subdirectories\n\n")
-		c.writeLinkedList(sb, subdirs, func(id string) string {
-			label := c.resolveNameWithPath(id) + "/"
-			return c.internalLink(id, label)
-		})
-	}
-
-	// Files
-	files := c.containsFile[c.node.ID]
-	if len(files) > 0 {
-		sb.WriteString("## Files\n\n")
-		c.writeLinkedList(sb, files, func(id string) string {
-			return c.internalLink(id, c.resolveName(id))
-		})
-	}
-}
-
-// --- FAQ Section ---
-
-func (c *renderContext) writeFAQSection(sb *strings.Builder) {
-	type faqEntry struct{ q, a string }
-	var faqs []faqEntry
-
-	name := getStr(c.node.Properties, "name")
-	if name == "" {
-		name = c.node.ID
-	}
-
-	switch c.label {
-	case "File":
-		path := getStr(c.node.Properties, "path")
-		fileName := getStr(c.node.Properties, "name")
-		if fileName == "" {
-			fileName = filepath.Base(path)
-		}
-		lang := getStr(c.node.Properties, "language")
-
-		// What does file do?
-		desc := fmt.Sprintf("%s is a source file in the %s codebase", fileName, c.repoName)
-		if lang != "" {
-			desc += fmt.Sprintf(", written in %s", lang)
-		}
-		desc += "."
-		if d, ok := c.belongsToDomain[c.node.ID]; ok {
-			desc += fmt.Sprintf(" It belongs to the %s domain", d)
-			if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-				desc += fmt.Sprintf(", %s subdomain", s)
-			}
-			desc += "."
-		}
-		faqs = append(faqs, faqEntry{fmt.Sprintf("What does %s do?", fileName), desc})
-
-		// Functions defined
-		funcs := c.definesFunc[c.node.ID]
-		if len(funcs) > 0 {
-			names := c.resolveNames(funcs)
-			sort.Strings(names)
-			listed := names
-			if len(listed) > 10 {
-				listed = listed[:10]
-			}
-			a := fmt.Sprintf("%s defines %d function(s): %s", fileName, len(funcs), strings.Join(listed, ", "))
-			if len(funcs) > 10 {
-				a += fmt.Sprintf(", and %d more", len(funcs)-10)
-			}
-			a += "."
-			faqs = append(faqs, faqEntry{fmt.Sprintf("What functions are defined in %s?", fileName), a})
-		}
-
-		// Dependencies
-		deps := c.imports[c.node.ID]
-		if len(deps) > 0 {
-			names := c.resolveNames(deps)
-			sort.Strings(names)
-			listed := names
-			if len(listed) > 8 {
-				listed = listed[:8]
-			}
-			a := fmt.Sprintf("%s imports %d module(s): %s", fileName, len(deps), strings.Join(listed, ", "))
-			if len(deps) > 8 {
-				a += fmt.Sprintf(", and %d more", len(deps)-8)
-			}
-			a += "."
-			faqs = append(faqs, faqEntry{fmt.Sprintf("What does %s depend on?", fileName), a})
-		}
-
-		// Imported by
-		ib := c.importedBy[c.node.ID]
-		if len(ib) > 0 {
-			names := c.resolveNames(ib)
-			sort.Strings(names)
-			listed := names
-			if len(listed) > 8 {
-				listed = listed[:8]
-			}
-			a := fmt.Sprintf("%s is imported by %d file(s): %s", fileName, len(ib), strings.Join(listed, ", "))
-			if len(ib) > 8 {
-				a += fmt.Sprintf(", and %d more", len(ib)-8)
-			}
-			a += "."
-			faqs = append(faqs, faqEntry{fmt.Sprintf("What files import %s?", fileName), a})
-		}
-
-		// Architecture position
-		archParts := []string{}
-		if d, ok := c.belongsToDomain[c.node.ID]; ok {
-			archParts = append(archParts, fmt.Sprintf("domain: %s", d))
-		}
-		if s, ok := c.belongsToSubdomain[c.node.ID]; ok {
-			archParts = append(archParts, fmt.Sprintf("subdomain: %s", s))
-		}
-		dir := filepath.Dir(path)
-		if dir != "" && dir != "." {
-			archParts = append(archParts, fmt.Sprintf("directory: %s", dir))
-		}
-		if len(archParts) > 0 {
-			faqs = append(faqs, faqEntry{
-				fmt.Sprintf("Where is %s in the architecture?", fileName),
-				fmt.Sprintf("%s is located at %s (%s).", fileName, path, strings.Join(archParts, ", ")),
-			})
-		}
-
-	case "Function":
-		funcName := name + "()"
-
-		// What does it do?
-		desc := fmt.Sprintf("%s is a function in the %s codebase", funcName, c.repoName)
-		if fileID, ok := c.fileOfFunc[c.node.ID]; ok {
-			desc += fmt.Sprintf(", defined in %s", c.resolveNameWithPath(fileID))
-		}
-		desc += "."
-		faqs = append(faqs, faqEntry{fmt.Sprintf("What does %s do?", funcName), desc})
-
-		// Where defined
-		if fileID, ok := c.fileOfFunc[c.node.ID]; ok {
-			filePath := c.resolveNameWithPath(fileID)
-			startLine := getNum(c.node.Properties, "startLine")
-			a := fmt.Sprintf("%s is defined in %s", funcName, filePath)
-			if startLine > 0 {
-				a += fmt.Sprintf(" at line %d", startLine)
-			}
-			a += "."
-			faqs = append(faqs, faqEntry{fmt.Sprintf("Where is %s defined?", funcName), a})
-		}
-
-		// What does it call?
-		called := c.calls[c.node.ID]
-		if len(called) > 0 {
-			names := c.resolveNames(called)
-			sort.Strings(names)
-			listed := names
-			if len(listed) > 8 {
-				listed = listed[:8]
-			}
-			a := fmt.Sprintf("%s calls %d function(s): %s", funcName, len(called), strings.Join(listed, ", "))
-			if len(called) > 8 {
-				a += fmt.Sprintf(", and %d more", len(called)-8)
-			}
-			a += "."
-			faqs = append(faqs, faqEntry{fmt.Sprintf("What does %s call?", funcName), a})
-		}
-
-		// What calls it?
-		callers := c.calledBy[c.node.ID]
-		if len(callers) > 0 {
-			names := c.resolveNames(callers)
-			sort.Strings(names)
-			listed := names
-			if len(listed) > 8 {
-				listed = listed[:8]
-			}
-			a := fmt.Sprintf("%s is called by %d function(s): %s", funcName, len(callers), strings.Join(listed, ", "))
-			if len(callers) > 8 {
-				a += fmt.Sprintf(", and %d more", len(callers)-8)
-			}
-			a += "."
-			faqs = append(faqs, faqEntry{fmt.Sprintf("What calls %s?", funcName), a})
-		}
-
-	case "Class":
-		className := name
-
-		desc := fmt.Sprintf("%s is a class in the %s codebase", className, c.repoName)
-		if fileID, ok := c.fileOfClass[c.node.ID]; ok {
-			desc += fmt.Sprintf(", defined in %s", c.resolveNameWithPath(fileID))
-		}
-		desc += "."
-		faqs = append(faqs, faqEntry{fmt.Sprintf("What is the %s class?", className), desc})
-
-		if fileID, ok := c.fileOfClass[c.node.ID]; ok {
-			filePath := c.resolveNameWithPath(fileID)
-			startLine := getNum(c.node.Properties, "startLine")
-			a := fmt.Sprintf("%s is defined in %s", className, filePath)
-			if startLine > 0 {
-				a += fmt.Sprintf(" at line %d", startLine)
-			}
-			a += "."
-			faqs = append(faqs, faqEntry{fmt.Sprintf("Where is %s defined?", className), a})
-		}
-
-		extends := c.extendsRel[c.node.ID]
-		if len(extends) > 0 {
-			names := c.resolveNames(extends)
-			faqs = append(faqs, faqEntry{
-				fmt.Sprintf("What does %s extend?", className),
-				fmt.Sprintf("%s extends %s.", className, strings.Join(names, ", ")),
-			})
-		}
-
-	case "Type":
-		typeName := name
-
-		desc := fmt.Sprintf("%s is a type/interface in the %s codebase", typeName, c.repoName)
-		if fileID, ok := c.fileOfType[c.node.ID]; ok {
-			desc += fmt.Sprintf(", defined in %s", c.resolveNameWithPath(fileID))
-		}
-		desc += "."
-		faqs = append(faqs, faqEntry{fmt.Sprintf("What is the %s type?", typeName), desc})
-
-		if fileID, ok := c.fileOfType[c.node.ID]; ok {
-			filePath := c.resolveNameWithPath(fileID)
-			startLine := getNum(c.node.Properties, "startLine")
-			a := fmt.Sprintf("%s is defined in %s", typeName, filePath)
-			if startLine > 0 {
-				a += fmt.Sprintf(" at line %d", startLine)
-			}
-			a += "."
-			faqs = append(faqs, faqEntry{fmt.Sprintf("Where is %s defined?", typeName), a})
-		}
-
-	case "Domain":
-		domainName := name
-		fileCount := len(c.domainFiles[domainName])
-		subs := c.domainSubdomains[domainName]
-
-		nodeDesc := getStr(c.node.Properties, "description")
-		desc := fmt.Sprintf("The %s domain is an architectural grouping in the %s codebase", domainName, c.repoName)
-		if nodeDesc != "" {
-			desc += ". " + nodeDesc
-		}
-		desc += fmt.Sprintf(" It contains %d source files.", fileCount)
-		faqs = append(faqs, faqEntry{fmt.Sprintf("What is the %s domain?", domainName), desc})
-
-		if len(subs) > 0 {
-			names := c.resolveNames(subs)
-			sort.Strings(names)
-			faqs = append(faqs, faqEntry{
-				fmt.Sprintf("What subdomains are in %s?", domainName),
-				fmt.Sprintf("The %s domain contains %d subdomain(s): %s.", domainName, len(subs), strings.Join(names, ", ")),
-			})
-		}
-
-		faqs = append(faqs, faqEntry{
-			fmt.Sprintf("How many files are in %s?", domainName),
-			fmt.Sprintf("The %s domain contains %d source files.", domainName, fileCount),
-		})
-
-	case "Subdomain":
-		subName := name
-		parentDomain := c.partOfDomain[c.node.ID]
-		fileCount := len(c.subdomainFiles[subName])
-		funcs := c.subdomainFuncs[subName]
-
-		nodeDesc := getStr(c.node.Properties, "description")
-		desc := fmt.Sprintf("%s is a subdomain in the %s codebase", subName, c.repoName)
-		if parentDomain != "" {
-			desc += fmt.Sprintf(", part of the %s domain", parentDomain)
-		}
-		if nodeDesc != "" {
-			desc += ". " + nodeDesc
-		}
-		desc += fmt.Sprintf(" It contains %d source files.", fileCount)
-		faqs = append(faqs, faqEntry{fmt.Sprintf("What is the %s subdomain?", subName), desc})
-
-		if parentDomain != "" {
-			faqs = append(faqs, faqEntry{
-				fmt.Sprintf("Which domain does %s belong to?", subName),
-				fmt.Sprintf("%s belongs to the %s domain.", subName, parentDomain),
-			})
-		}
-
-		if len(funcs) > 0 {
-			names := c.resolveNames(funcs)
-			sort.Strings(names)
-			listed := names
-			if len(listed) > 8 {
-				listed = listed[:8]
-			}
-			a := fmt.Sprintf("The %s subdomain contains %d function(s): %s", subName, len(funcs), strings.Join(listed, ", "))
-			if len(funcs) > 8 {
-				a += fmt.Sprintf(", and %d more", len(funcs)-8)
-			}
-			a += "."
-			faqs = append(faqs, faqEntry{fmt.Sprintf("What functions are in %s?", subName), a})
-		}
-
-	case "Directory":
-		dirName := getStr(c.node.Properties, "name")
-		if dirName == "" {
-			dirName = filepath.Base(getStr(c.node.Properties, "path"))
-		}
-		files := c.containsFile[c.node.ID]
-		subdirs := c.childDir[c.node.ID]
-
-		desc := fmt.Sprintf("The %s/ directory contains %d files and %d subdirectories in the %s codebase.", dirName, len(files), len(subdirs), c.repoName)
-		faqs = append(faqs, faqEntry{fmt.Sprintf("What's in the %s/ directory?", dirName), desc})
-
-		if len(subdirs) > 0 {
-			names := c.resolveNames(subdirs)
-			sort.Strings(names)
-			faqs = append(faqs, faqEntry{
-				fmt.Sprintf("What subdirectories does %s/ contain?", dirName),
-				fmt.Sprintf("%s/ contains %d subdirectory(ies): %s.", dirName, len(subdirs), strings.Join(names, ", ")),
-			})
-		}
-	}
-
-	// Require minimum 2 FAQs
-	if len(faqs) < 2 {
-		return
-	}
-
-	sb.WriteString("## FAQs\n\n")
-	for _, faq := range faqs {
-		sb.WriteString(fmt.Sprintf("### %s\n\n%s\n\n", faq.q, faq.a))
-	}
-}
-
-// --- Graph Data (frontmatter) ---
-
-type graphNode struct {
-	ID    string `json:"id"`
-	Label string `json:"label"`
-	Type  string `json:"type"`
-	Slug  string `json:"slug"`
-}
-
-type graphEdge struct {
-	Source string `json:"source"`
-	Target string `json:"target"`
-	Type   string `json:"type"`
-}
-
-type graphData struct {
-	Nodes []graphNode `json:"nodes"`
-	Edges []graphEdge `json:"edges"`
-}
-
-func (c *renderContext) writeGraphData(sb *strings.Builder) {
-	var nodes []graphNode
-	var edges []graphEdge
-	seen := make(map[string]bool)
-
-	addNode := func(nodeID string) {
-		if seen[nodeID] || len(seen) >= 31 { // center + 30 neighbors
-			return
-		}
-		n := c.nodeLookup[nodeID]
-		if n == nil {
-			return
-		}
-		seen[nodeID] = true
-		label := getStr(n.Properties, "name")
-		if label == "" {
-			label = nodeID
-		}
-		nodeType := ""
-		if len(n.Labels) > 0 {
-			nodeType = n.Labels[0]
-		}
-		nodes = append(nodes, graphNode{
-			ID:    nodeID,
-			Label: label,
-			Type:  nodeType,
-			Slug:  c.slugLookup[nodeID],
-		})
-	}
-
-	addEdge := func(from, to, relType string) {
-		edges = append(edges, graphEdge{Source: from, Target: to, Type: relType})
-	}
-
-	// Add center node
-	addNode(c.node.ID)
-
-	// Collect neighbor relationships
-	relSets := []struct {
-		ids     []string
-		relType string
-		reverse bool // if true, edge goes neighbor -> center
-	}{
-		{c.imports[c.node.ID], "imports", false},
-		{c.importedBy[c.node.ID], "imports", true},
-		{c.calls[c.node.ID], "calls", false},
-		{c.calledBy[c.node.ID], "calls", true},
-		{c.definesFunc[c.node.ID], "defines", false},
-		{c.declaresClass[c.node.ID], "defines", false},
-		{c.definesType[c.node.ID], "defines", false},
-		{c.extendsRel[c.node.ID], "extends", false},
-		{c.containsFile[c.node.ID], "contains", false},
-		{c.childDir[c.node.ID], "contains", false},
-	}
-
-	// Add file-of reverse lookups
-	if fileID, ok := c.fileOfFunc[c.node.ID]; ok {
-		relSets = append(relSets, struct {
-			ids     []string
-			relType string
-			reverse bool
-		}{[]string{fileID}, "defines", true})
-	}
-	if fileID, ok := c.fileOfClass[c.node.ID]; ok {
-		relSets = append(relSets, struct {
-			ids     []string
-			relType string
-			reverse bool
-		}{[]string{fileID}, "defines", true})
-	}
-	if fileID, ok := c.fileOfType[c.node.ID]; ok {
-		relSets = append(relSets, struct {
-			ids     []string
-			relType string
-			reverse bool
-		}{[]string{fileID}, "defines", true})
-	}
-
-	// Domain/subdomain neighbors
-	if domName, ok := c.belongsToDomain[c.node.ID]; ok {
-		if domNodeID, ok := c.domainNodeByName[domName]; ok {
-			relSets = append(relSets, struct {
-				ids     []string
-				relType string
-				reverse bool
-			}{[]string{domNodeID}, "belongsTo", false})
-		}
-	}
-	if subName, ok := c.belongsToSubdomain[c.node.ID]; ok {
-		if subNodeID, ok := c.subdomainNodeByName[subName]; ok {
-			relSets = append(relSets, struct {
-				ids     []string
-				relType string
-				reverse bool
-			}{[]string{subNodeID}, "belongsTo", false})
-		}
-	}
-
-	// For domains: add subdomain children
-	if c.label == "Domain" {
-		domName := getStr(c.node.Properties, "name")
-		relSets = append(relSets, struct {
-			ids     []string
-			relType string
-			reverse bool
-		}{c.domainSubdomains[domName], "contains", false})
-	}
-	// For subdomains: add domain parent
-	if c.label == "Subdomain" {
-		if parentDom := c.partOfDomain[c.node.ID]; parentDom != "" {
-			if domNodeID, ok := c.domainNodeByName[parentDom]; ok {
-				relSets = append(relSets, struct {
-					ids     []string
-					relType string
-					reverse bool
-				}{[]string{domNodeID}, "partOf", false})
-			}
-		}
-	}
-
-	for _, rs := range relSets {
-		for _, id := range rs.ids {
-			if len(seen) >= 31 {
-				break
-			}
-			addNode(id)
-			if !seen[id] {
-				continue // node wasn't added (cap reached before)
-			}
-			if rs.reverse {
-				addEdge(id, c.node.ID, rs.relType)
-			} else {
-				addEdge(c.node.ID, id, rs.relType)
-			}
-		}
-	}
-
-	if len(nodes) < 2 {
-		return // no neighbors, skip
-	}
-
-	gd := graphData{Nodes: nodes, Edges: edges}
-	data, err := json.Marshal(gd)
-	if err != nil {
-		return
-	}
-	sb.WriteString(fmt.Sprintf("graph_data: %q\n", string(data)))
-}
-
-// --- Mermaid Diagram (frontmatter) ---
-
-func mermaidEscape(s string) string {
-	// Escape special chars for Mermaid node labels
-	s = strings.ReplaceAll(s, `"`, "#quot;")
-	s = strings.ReplaceAll(s, `<`, "#lt;")
-	s = strings.ReplaceAll(s, `>`, "#gt;")
-	return s
-}
-
-func mermaidID(nodeID string) string {
-	// Create valid Mermaid node ID from arbitrary string
-	id := strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
-			return r
-		}
-		return '_'
-	}, nodeID)
-	if id == "" {
-		id = "node"
-	}
-	return id
-}
-
-func (c *renderContext) writeMermaidDiagram(sb *strings.Builder) {
-	var lines []string
-	centerID := mermaidID(c.node.ID)
-	centerLabel := mermaidEscape(getStr(c.node.Properties, "name"))
-	if centerLabel == "" {
-		centerLabel = mermaidEscape(c.node.ID)
-	}
-	nodeCount := 0
-	maxNodes := 15
-
-	addedNodes := make(map[string]bool)
-
-	addNode := func(nodeID, label string) string {
-		mid := mermaidID(nodeID)
-		if !addedNodes[mid] {
-			addedNodes[mid] = true
-			nodeCount++
-		}
-		return mid
-	}
-
-	switch c.label {
-	case "File":
-		lines = append(lines, "graph LR")
-		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, centerLabel))
-		addedNodes[centerID] = true
-		nodeCount++
-
-		// Imports
-		for _, id := range c.imports[c.node.ID] {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(id))
-			mid := addNode(id, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
-		}
-		// ImportedBy
-		for _, id := range c.importedBy[c.node.ID] {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(id))
-			mid := addNode(id, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s --> %s", mid, centerID))
-		}
-
-	case "Function":
-		lines = append(lines, "graph TD")
-		lines = append(lines, fmt.Sprintf("  %s[\"%s()\"]", centerID, centerLabel))
-		addedNodes[centerID] = true
-		nodeCount++
-
-		// File it's defined in
-		if fileID, ok := c.fileOfFunc[c.node.ID]; ok {
-			if nodeCount < maxNodes {
-				label := mermaidEscape(c.resolveName(fileID))
-				mid := addNode(fileID, label)
-				lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
-				lines = append(lines, fmt.Sprintf("  %s -->|defined in| %s", centerID, mid))
-			}
-		}
-
-		for _, id := range c.calledBy[c.node.ID] {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(id))
-			mid := addNode(id, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s()\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s -->|calls| %s", mid, centerID))
-		}
-		for _, id := range c.calls[c.node.ID] {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(id))
-			mid := addNode(id, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s()\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s -->|calls| %s", centerID, mid))
-		}
-
-	case "Type":
-		lines = append(lines, "graph TD")
-		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, centerLabel))
-		addedNodes[centerID] = true
-		nodeCount++
-
-		// File it's defined in
-		if fileID, ok := c.fileOfType[c.node.ID]; ok {
-			if nodeCount < maxNodes {
-				label := mermaidEscape(c.resolveName(fileID))
-				mid := addNode(fileID, label)
-				lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
-				lines = append(lines, fmt.Sprintf("  %s -->|defined in| %s", centerID, mid))
-			}
-		}
-
-	case "Class":
-		lines = append(lines, "graph TD")
-		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, centerLabel))
-		addedNodes[centerID] = true
-		nodeCount++
-
-		// Parent classes (extends)
-		for _, id := range c.extendsRel[c.node.ID] {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(id))
-			mid := addNode(id, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s -->|extends| %s", centerID, mid))
-		}
-
-		// File it's defined in
-		if fileID, ok := c.fileOfClass[c.node.ID]; ok {
-			if nodeCount < maxNodes {
-				label := mermaidEscape(c.resolveName(fileID))
-				mid := addNode(fileID, label)
-				lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
-				lines = append(lines, fmt.Sprintf("  %s -->|defined in| %s", centerID, mid))
-			}
-		}
-
-		// Methods defined on this class
-		for _, id := range c.definesFunc[c.node.ID] {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(id))
-			mid := addNode(id, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s()\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s -->|method| %s", centerID, mid))
-		}
-
-	case "Domain":
-		lines = append(lines, "graph TD")
-		domName := getStr(c.node.Properties, "name")
-		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, mermaidEscape(domName)))
-		addedNodes[centerID] = true
-		nodeCount++
-
-		for _, subID := range c.domainSubdomains[domName] {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(subID))
-			mid := addNode(subID, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
-		}
-
-	case "Subdomain":
-		lines = append(lines, "graph TD")
-		subName := getStr(c.node.Properties, "name")
-		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, mermaidEscape(subName)))
-		addedNodes[centerID] = true
-		nodeCount++
-
-		files := c.subdomainFiles[subName]
-		for _, fID := range files {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(fID))
-			mid := addNode(fID, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
-		}
-
-	case "Directory":
-		lines = append(lines, "graph TD")
-		dirName := getStr(c.node.Properties, "name")
-		if dirName == "" {
-			dirName = filepath.Base(getStr(c.node.Properties, "path"))
-		}
-		lines = append(lines, fmt.Sprintf("  %s[\"%s/\"]", centerID, mermaidEscape(dirName)))
-		addedNodes[centerID] = true
-		nodeCount++
-
-		for _, id := range c.childDir[c.node.ID] {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(id))
-			mid := addNode(id, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s/\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
-		}
-		for _, id := range c.containsFile[c.node.ID] {
-			if nodeCount >= maxNodes {
-				break
-			}
-			label := mermaidEscape(c.resolveName(id))
-			mid := addNode(id, label)
-			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
-			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
-		}
-
-	default:
-		return
-	}
-
-	// Style the center node
-	if len(lines) > 1 && c.label != "Class" {
-		lines = append(lines, fmt.Sprintf("  style %s fill:#6366f1,stroke:#818cf8,color:#fff", centerID))
-	}
-
-	if nodeCount < 2 {
-		return
-	}
-
-	diagram := strings.Join(lines, "\n")
-	sb.WriteString(fmt.Sprintf("mermaid_diagram: %q\n", diagram))
-}
-
-// --- Architecture Map (frontmatter) ---
-
-func (c *renderContext) writeArchMap(sb *strings.Builder) {
-	archMap := make(map[string]interface{})
-
-	// Domain
-	if domName, ok := c.belongsToDomain[c.node.ID]; ok && domName != "" {
-		entry := map[string]string{"name": domName}
-		if domNodeID, ok := c.domainNodeByName[domName]; ok {
-			entry["slug"] = c.slugLookup[domNodeID]
-		}
-		archMap["domain"] = entry
-	}
-
-	// Subdomain
-	if subName, ok := c.belongsToSubdomain[c.node.ID]; ok && subName != "" {
-		entry := map[string]string{"name": subName}
-		if subNodeID, ok := c.subdomainNodeByName[subName]; ok {
-			entry["slug"] = c.slugLookup[subNodeID]
-		}
-		archMap["subdomain"] = entry
-	}
-
-	// File (for functions/classes/types)
-	switch c.label {
-	case "Function":
-		if fileID, ok := c.fileOfFunc[c.node.ID]; ok {
-			archMap["file"] = map[string]string{
-				"name": c.resolveName(fileID),
-				"slug": c.slugLookup[fileID],
-			}
-		}
-	case "Class":
-		if fileID, ok := c.fileOfClass[c.node.ID]; ok {
-			archMap["file"] = map[string]string{
-				"name": c.resolveName(fileID),
-				"slug": c.slugLookup[fileID],
-			}
-		}
-	case "Type":
-		if fileID, ok := c.fileOfType[c.node.ID]; ok {
-			archMap["file"] = map[string]string{
-				"name": c.resolveName(fileID),
-				"slug": c.slugLookup[fileID],
-			}
-		}
-	}
-
-	// Entity itself
-	name := getStr(c.node.Properties, "name")
-	if name == "" {
-		name = c.node.ID
-	}
-	archMap["entity"] = map[string]string{
-		"name": name,
-		"type": c.label,
-		"slug": c.slug,
-	}
-
-	if len(archMap) < 2 {
-		return // just the entity itself, not useful
-	}
-
-	data, err := json.Marshal(archMap)
-	if err != nil {
-		return
-	}
-	sb.WriteString(fmt.Sprintf("arch_map: %q\n", string(data)))
-}
-
-// writeLinkedList writes a sorted list of linked items.
-func (c *renderContext) writeLinkedList(sb *strings.Builder, nodeIDs []string, linkFn func(string) string) {
-	type sortItem struct {
-		label string
-		id    string
-	}
-	items := make([]sortItem, 0, len(nodeIDs))
-	for _, id := range nodeIDs {
-		items = append(items, sortItem{label: c.resolveName(id), id: id})
-	}
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].label < items[j].label
-	})
-	for _, item := range items {
-		sb.WriteString(fmt.Sprintf("- %s\n", linkFn(item.id)))
-	}
-	sb.WriteString("\n")
-}
-
-// --- Tag generation ---
-
-func (c *renderContext) writeTags(sb *strings.Builder) {
-	var tags []string
-
-	for _, label := range c.node.Labels {
-		tags = append(tags, label)
-	}
-
-	if lang := getStr(c.node.Properties, "language"); lang != "" {
-		tags = append(tags, lang)
-	}
-
-	ibCount := len(c.importedBy[c.node.ID])
-	impCount := len(c.imports[c.node.ID])
-	cbCount := len(c.calledBy[c.node.ID])
-
-	if ibCount >= 5 || cbCount >= 5 {
-		tags = append(tags, "High-Dependency")
-	}
-	if impCount >= 5 {
-		tags = append(tags, "Many-Imports")
-	}
-
-	funcCount := len(c.definesFunc[c.node.ID])
-	classCount := len(c.declaresClass[c.node.ID])
-	if funcCount >= 10 || classCount >= 5 {
-		tags = append(tags, "Complex")
-	}
-
-	if ibCount == 0 && impCount == 0 && cbCount == 0 && c.label == "File" {
-		tags = append(tags, "Isolated")
-	}
-
-	if len(tags) > 0 {
-		sb.WriteString("tags:\n")
-		for _, t := range tags {
-			sb.WriteString(fmt.Sprintf("  - %q\n", t))
-		}
-	}
-}
-
-// --- Helpers ---
-
-func (c *renderContext) resolveName(nodeID string) string {
-	n := c.nodeLookup[nodeID]
-	if n == nil {
-		return nodeID
-	}
-	name := getStr(n.Properties, "name")
-	if name == "" {
-		return nodeID
-	}
-	return name
-}
-
-func (c *renderContext) resolveNames(nodeIDs []string) []string {
-	result := make([]string, 0, len(nodeIDs))
-	for _, id := range nodeIDs {
-		result = append(result, c.resolveName(id))
-	}
-	return result
-}
-
-func (c *renderContext) resolveNameWithPath(nodeID string) string {
-	n := c.nodeLookup[nodeID]
-	if n == nil {
-		return nodeID
-	}
-	path := getStr(n.Properties, "path")
-	if path == "" {
-		path = getStr(n.Properties, "filePath")
-	}
-	name := getStr(n.Properties, "name")
-	if path != "" {
-		return path
-	} else if name != "" {
-		return name
-	}
-	return nodeID
-}
-
-func (c *renderContext) resolveNamesWithPaths(nodeIDs []string) []string {
-	result := make([]string, 0, len(nodeIDs))
-	for _, id := range nodeIDs {
-		result = append(result, c.resolveNameWithPath(id))
-	}
-	return result
-}
-
-func loadGraph(path string) ([]Node, []Relationship, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	log.Printf("  File size: %d bytes", len(data))
-
-	var resp APIResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		log.Printf("  APIResponse unmarshal error: %v", err)
-	} else if resp.Result == nil {
-		log.Printf("  APIResponse parsed but Result is nil (status=%s)", resp.Status)
-	} else {
-		g := resp.Result.Graph
-		log.Printf("  APIResponse parsed: %d nodes, %d rels", len(g.Nodes), len(g.Relationships))
-		return g.Nodes, g.Relationships, nil
-	}
-
-	var result GraphResult
-	if err := json.Unmarshal(data, &result); err == nil && len(result.Graph.Nodes) > 0 {
-		return result.Graph.Nodes, result.Graph.Relationships, nil
-	}
-
-	var graph Graph
-	if err := json.Unmarshal(data, &graph); err == nil && len(graph.Nodes) > 0 {
-		return graph.Nodes, graph.Relationships, nil
-	}
-
-	return nil, nil, fmt.Errorf("unrecognized graph format")
-}
-
-func generateSlug(node Node, label string) string {
-	props := node.Properties
-
-	switch label {
-	case "File":
-		path := getStr(props, "path")
-		if path == "" {
-			return ""
-		}
-		return toSlug("file-" + path)
-	case "Function":
-		name := getStr(props, "name")
-		filePath := getStr(props, "filePath")
-		if name == "" {
-			return ""
-		}
-		if filePath != "" {
-			return toSlug("fn-" + filepath.Base(filePath) + "-" + name)
-		}
-		return toSlug("fn-" + name)
-	case "Class":
-		name := getStr(props, "name")
-		filePath := getStr(props, "filePath")
-		if name == "" {
-			return ""
-		}
-		if filePath != "" {
-			return toSlug("class-" + filepath.Base(filePath) + "-" + name)
-		}
-		return toSlug("class-" + name)
-	case "Type":
-		name := getStr(props, "name")
-		filePath := getStr(props, "filePath")
-		if name == "" {
-			return ""
-		}
-		if filePath != "" {
-			return toSlug("type-" + filepath.Base(filePath) + "-" + name)
-		}
-		return toSlug("type-" + name)
-	case "Domain":
-		name := getStr(props, "name")
-		if name == "" {
-			return ""
-		}
-		return toSlug("domain-" + name)
-	case "Subdomain":
-		name := getStr(props, "name")
-		if name == "" {
-			return ""
-		}
-		return toSlug("subdomain-" + name)
-	case "Directory":
-		path := getStr(props, "path")
-		if path == "" || strings.Contains(path, "/app/repo-root/") {
-			return ""
-		}
-		return toSlug("dir-" + path)
-	default:
-		return ""
-	}
-}
-
-func hasLabel(node *Node, label string) bool {
-	for _, l := range node.Labels {
-		if l == label {
-			return true
-		}
-	}
-	return false
-}
-
-func getStr(m map[string]interface{}, key string) string {
-	v, ok := m[key]
-	if !ok {
-		return ""
-	}
-	s, ok := v.(string)
-	if !ok {
-		return ""
-	}
-	return s
-}
-
-func getNum(m map[string]interface{}, key string) int {
-	v, ok := m[key]
-	if !ok {
-		return 0
-	}
-	switch n := v.(type) {
-	case float64:
-		return int(n)
-	case int:
-		return n
 	}
-	return 0
+	log.Printf("Redirects: %d renamed pages", len(list))
+	return failed
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/supermodeltools/graph2md/pkg/graph2md"
+)
+
+func TestParseInputs(t *testing.T) {
+	tests := []struct {
+		list string
+		want []input
+	}{
+		{"a.json, b.json.gz ,", []input{{path: "a.json"}, {path: "b.json.gz"}}},
+		{"svc-a=a.json,svc_b = b.json", []input{{repo: "svc-a", path: "a.json"}, {repo: "svc_b", path: "b.json"}}},
+		{"-", []input{{path: "-"}}},
+		{"out/key=value/graph.json", []input{{path: "out/key=value/graph.json"}}},
+		{"svc=out/key=value/graph.json", []input{{repo: "svc", path: "out/key=value/graph.json"}}},
+		{"my.repo=graph.json", []input{{path: "my.repo=graph.json"}}},
+		{`C:\graphs\a=b.json`, []input{{path: `C:\graphs\a=b.json`}}},
+	}
+	for _, tt := range tests {
+		if got := parseInputs(tt.list); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseInputs(%q) = %+v, want %+v", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestJobExitCode(t *testing.T) {
+	result := &graph2md.GraphResult{}
+	tests := []struct {
+		name   string
+		resp   graph2md.APIResponse
+		strict bool
+		only   bool
+		code   int
+		now    bool
+	}{
+		{"completed", graph2md.APIResponse{Status: "completed", Result: result}, true, true, 0, false},
+		{"pending, only input", graph2md.APIResponse{Status: "pending"}, false, true, 4, true},
+		{"pending, one of several", graph2md.APIResponse{Status: "queued"}, false, false, 4, false},
+		{"pending, strict", graph2md.APIResponse{Status: "pending"}, true, false, 4, true},
+		{"processing, only input", graph2md.APIResponse{Status: "running"}, false, true, 5, true},
+		{"processing, one of several", graph2md.APIResponse{Status: "processing"}, false, false, 5, false},
+		{"failed without result", graph2md.APIResponse{Status: "failed"}, false, false, 3, false},
+		{"failed without result, only input", graph2md.APIResponse{Error: json.RawMessage(`"boom"`)}, false, true, 3, true},
+		{"failed with partial result", graph2md.APIResponse{Status: "failed", Result: result}, false, true, 0, false},
+		{"failed with partial result, strict", graph2md.APIResponse{Status: "failed", Result: result}, true, false, 3, true},
+		{"unknown status", graph2md.APIResponse{Status: "paused"}, true, true, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, now := jobExitCode(&tt.resp, tt.strict, tt.only)
+			if code != tt.code || now != tt.now {
+				t.Errorf("jobExitCode = %d, %t; want %d, %t", code, now, tt.code, tt.now)
+			}
+		})
+	}
+}
+
+func TestParseRedirectModes(t *testing.T) {
+	tests := []struct {
+		list     string
+		links    graph2md.LinkStyle
+		manifest string
+		want     map[string]bool
+		wantErr  string
+	}{
+		{"", graph2md.LinkMarkdown, "", map[string]bool{}, ""},
+		{"aliases, html,netlify", graph2md.LinkPretty, "manifest.json", map[string]bool{"aliases": true, "html": true, "netlify": true}, ""},
+		{"html,redirect", graph2md.LinkHTML, "manifest.json", nil, "unknown -redirects mode"},
+		{"aliases", graph2md.LinkMarkdown, "manifest.json", nil, "-links md"},
+		{"netlify", graph2md.LinkMarkdown, "manifest.json", nil, "-links md"},
+		{"html", graph2md.LinkHTML, "", nil, "-manifest"},
+	}
+	for _, tt := range tests {
+		got, err := parseRedirectModes(tt.list, tt.links, tt.manifest)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseRedirectModes(%q, %s, %q) error = %v, want %q", tt.list, tt.links, tt.manifest, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseRedirectModes(%q, %s, %q) = %v, %v; want %v", tt.list, tt.links, tt.manifest, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package graph2md
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// --- Body writers ---
+
+func (c *renderContext) writeFileBody(sb *strings.Builder) {
+	props := c.node.Properties
+	path := getStr(props, "path")
+
+	// Domain link
+	if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+		sb.WriteString("## Domain\n\n")
+		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(d)))
+		sb.WriteString("\n")
+
+		// Subdomain link (only show if domain exists)
+		if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+			sb.WriteString("## Subdomains\n\n")
+			sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(s)))
+			sb.WriteString("\n")
+		}
+	}
+
+	// Functions defined in this file
+	funcs := c.DefinesFunc[c.node.ID]
+	if len(funcs) > 0 {
+		sb.WriteString("## Functions\n\n")
+		c.writeLinkedList(sb, funcs, func(id string) string {
+			name := c.resolveName(id)
+			return c.internalLink(id, name+"()")
+		})
+	}
+
+	// Classes defined in this file
+	classes := c.DeclaresClass[c.node.ID]
+	if len(classes) > 0 {
+		sb.WriteString("## Classes\n\n")
+		c.writeLinkedList(sb, classes, func(id string) string {
+			return c.internalLink(id, c.resolveName(id))
+		})
+	}
+
+	// Types defined in this file
+	types := c.DefinesType[c.node.ID]
+	if len(types) > 0 {
+		sb.WriteString("## Types\n\n")
+		c.writeLinkedList(sb, types, func(id string) string {
+			return c.internalLink(id, c.resolveName(id))
+		})
+	}
+
+	// Dependencies
+	deps := c.Imports[c.node.ID]
+	if len(deps) > 0 {
+		sb.WriteString("## Dependencies\n\n")
+		c.writeLinkedList(sb, deps, func(id string) string {
+			return c.internalLink(id, c.resolveName(id))
+		})
+	}
+
+	// Imported By
+	ib := c.ImportedBy[c.node.ID]
+	if len(ib) > 0 {
+		sb.WriteString("## Imported By\n\n")
+		c.writeLinkedList(sb, ib, func(id string) string {
+			return c.internalLink(id, c.resolveNameWithPath(id))
+		})
+	}
+
+	// Source link
+	c.writeSourceSection(sb, path, 0, 0)
+}
+
+func (c *renderContext) writeFunctionBody(sb *strings.Builder) {
+	props := c.node.Properties
+	filePath := getStr(props, "filePath")
+	startLine := getNum(props, "startLine")
+	endLine := getNum(props, "endLine")
+
+	// Defined In
+	if fileID, ok := c.FileOfFunc[c.node.ID]; ok {
+		sb.WriteString("## Defined In\n\n")
+		sb.WriteString(fmt.Sprintf("- %s\n", c.internalLink(fileID, c.resolveNameWithPath(fileID))))
+		sb.WriteString("\n")
+	}
+
+	// Domain link
+	if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+		sb.WriteString("## Domain\n\n")
+		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(d)))
+		sb.WriteString("\n")
+
+		if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+			sb.WriteString("## Subdomains\n\n")
+			sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(s)))
+			sb.WriteString("\n")
+		}
+	}
+
+	// Calls
+	called := c.Calls[c.node.ID]
+	if len(called) > 0 {
+		sb.WriteString("## Calls\n\n")
+		c.writeLinkedList(sb, called, func(id string) string {
+			name := c.resolveName(id)
+			return c.internalLink(id, name+"()")
+		})
+	}
+
+	// Called By
+	callers := c.CalledBy[c.node.ID]
+	if len(callers) > 0 {
+		sb.WriteString("## Called By\n\n")
+		c.writeLinkedList(sb, callers, func(id string) string {
+			name := c.resolveName(id)
+			return c.internalLink(id, name+"()")
+		})
+	}
+
+	// Source
+	c.writeSourceSection(sb, filePath, startLine, endLine)
+}
+
+func (c *renderContext) writeClassBody(sb *strings.Builder) {
+	props := c.node.Properties
+	filePath := getStr(props, "filePath")
+	startLine := getNum(props, "startLine")
+	endLine := getNum(props, "endLine")
+
+	// Defined In
+	if fileID, ok := c.FileOfClass[c.node.ID]; ok {
+		sb.WriteString("## Defined In\n\n")
+		sb.WriteString(fmt.Sprintf("- %s\n", c.internalLink(fileID, c.resolveNameWithPath(fileID))))
+		sb.WriteString("\n")
+	}
+
+	// Domain link
+	if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+		sb.WriteString("## Domain\n\n")
+		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(d)))
+		sb.WriteString("\n")
+
+		if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+			sb.WriteString("## Subdomains\n\n")
+			sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(s)))
+			sb.WriteString("\n")
+		}
+	}
+
+	// Extends
+	extends := c.Extends[c.node.ID]
+	if len(extends) > 0 {
+		sb.WriteString("## Extends\n\n")
+		for _, id := range extends {
+			sb.WriteString(fmt.Sprintf("- %s\n", c.internalLink(id, c.resolveName(id))))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Source
+	c.writeSourceSection(sb, filePath, startLine, endLine)
+}
+
+func (c *renderContext) writeTypeBody(sb *strings.Builder) {
+	props := c.node.Properties
+	filePath := getStr(props, "filePath")
+	startLine := getNum(props, "startLine")
+	endLine := getNum(props, "endLine")
+
+	// Defined In
+	if fileID, ok := c.FileOfType[c.node.ID]; ok {
+		sb.WriteString("## Defined In\n\n")
+		sb.WriteString(fmt.Sprintf("- %s\n", c.internalLink(fileID, c.resolveNameWithPath(fileID))))
+		sb.WriteString("\n")
+	}
+
+	// Domain link
+	if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+		sb.WriteString("## Domain\n\n")
+		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(d)))
+		sb.WriteString("\n")
+
+		if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+			sb.WriteString("## Subdomains\n\n")
+			sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(s)))
+			sb.WriteString("\n")
+		}
+	}
+
+	c.writeSourceSection(sb, filePath, startLine, endLine)
+}
+
+func (c *renderContext) writeDomainBody(sb *strings.Builder) {
+	// Subdomains
+	subs := c.DomainSubdomains[c.node.ID]
+	if len(subs) > 0 {
+		sb.WriteString("## Subdomains\n\n")
+		c.writeLinkedList(sb, subs, func(id string) string {
+			return c.internalLink(id, c.resolveName(id))
+		})
+	}
+
+	// Source Files
+	files := c.DomainFiles[c.node.ID]
+	if len(files) > 0 {
+		sb.WriteString("## Source Files\n\n")
+		c.writeLinkedList(sb, files, func(id string) string {
+			return c.internalLink(id, c.resolveNameWithPath(id))
+		})
+	}
+}
+
+func (c *renderContext) writeSubdomainBody(sb *strings.Builder) {
+	// Domain link
+	if parentDomain := c.PartOfDomain[c.node.ID]; parentDomain != "" {
+		sb.WriteString("## Domain\n\n")
+		sb.WriteString(fmt.Sprintf("- %s\n", c.domainLink(parentDomain)))
+		sb.WriteString("\n")
+	}
+
+	// Functions in this subdomain
+	funcs := c.SubdomainFuncs[c.node.ID]
+	if len(funcs) > 0 {
+		sb.WriteString("## Functions\n\n")
+		c.writeLinkedList(sb, funcs, func(id string) string {
+			fnName := c.resolveName(id)
+			return c.internalLink(id, fnName+"()")
+		})
+	}
+
+	// Classes in this subdomain
+	classes := c.SubdomainClasses[c.node.ID]
+	if len(classes) > 0 {
+		sb.WriteString("## Classes\n\n")
+		c.writeLinkedList(sb, classes, func(id string) string {
+			return c.internalLink(id, c.resolveName(id))
+		})
+	}
+
+	// Source Files
+	files := c.SubdomainFiles[c.node.ID]
+	if len(files) > 0 {
+		sb.WriteString("## Source Files\n\n")
+		c.writeLinkedList(sb, files, func(id string) string {
+			return c.internalLink(id, c.resolveNameWithPath(id))
+		})
+	}
+}
+
+func (c *renderContext) writeDirectoryBody(sb *strings.Builder) {
+	// Subdirectories
+	subdirs := c.ChildDir[c.node.ID]
+	if len(subdirs) > 0 {
+		sb.WriteString("## Subdirectories\n\n")
+		c.writeLinkedList(sb, subdirs, func(id string) string {
+			label := c.resolveNameWithPath(id) + "/"
+			return c.internalLink(id, label)
+		})
+	}
+
+	// Files
+	files := c.ContainsFile[c.node.ID]
+	if len(files) > 0 {
+		sb.WriteString("## Files\n\n")
+		c.writeLinkedList(sb, files, func(id string) string {
+			return c.internalLink(id, c.resolveName(id))
+		})
+	}
+}
+
+// writeLinkedList writes a sorted list of linked items.
+func (c *renderContext) writeLinkedList(sb *strings.Builder, nodeIDs []string, linkFn func(string) string) {
+	type sortItem struct {
+		label string
+		id    string
+	}
+	items := make([]sortItem, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		items = append(items, sortItem{label: c.resolveName(id), id: id})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].label < items[j].label
+	})
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("- %s\n", linkFn(item.id)))
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,229 @@
+package graph2md
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// edgeVerbs describes an added or removed edge from each endpoint's side.
+var edgeVerbs = map[string]struct{ from, to string }{
+	"calls":   {"calls", "called by"},
+	"IMPORTS": {"imports", "imported by"},
+}
+
+// entityChange is one line of an entity page's Changes section.
+type entityChange struct {
+	text  string // change description, before the linked entity if any
+	other *EntityRef
+}
+
+// entityChanges returns the changes in Diff that concern each entity, keyed
+// by node ID.
+func (r *Renderer) entityChanges() map[string][]entityChange {
+	r.changesOnce.Do(func() {
+		d := r.Diff
+		changes := make(map[string][]entityChange)
+		if d == nil {
+			r.changes = changes
+			return
+		}
+		add := func(id, text string, other *EntityRef) {
+			changes[id] = append(changes[id], entityChange{text: text, other: other})
+		}
+		for _, e := range d.Added {
+			add(e.ID, "Added", nil)
+		}
+		for _, m := range d.Moved {
+			add(m.ID, fmt.Sprintf("Moved from %s", r.text(m.OldPath)), nil)
+		}
+		for _, re := range d.Reassigned {
+			if re.OldDomain != re.NewDomain {
+				add(re.ID, fmt.Sprintf("Domain changed from %s to %s", r.orNone(re.OldDomain), r.orNone(re.NewDomain)), nil)
+			}
+			if re.OldSubdomain != re.NewSubdomain {
+				add(re.ID, fmt.Sprintf("Subdomain changed from %s to %s", r.orNone(re.OldSubdomain), r.orNone(re.NewSubdomain)), nil)
+			}
+		}
+		for _, e := range d.AddedEdges {
+			v := edgeVerbs[e.Type]
+			add(e.From.ID, "Now "+v.from, &e.To)
+			add(e.To.ID, "Now "+v.to, &e.From)
+		}
+		for _, e := range d.RemovedEdges {
+			v := edgeVerbs[e.Type]
+			add(e.From.ID, "No longer "+v.from, &e.To)
+			add(e.To.ID, "No longer "+v.to, &e.From)
+		}
+		r.changes = changes
+	})
+	return r.changes
+}
+
+// writeChangesSection lists what changed about the entity since the
+// snapshot Diff was computed against.
+func (c *renderContext) writeChangesSection(sb *strings.Builder) {
+	changes := c.entityChanges()[c.node.ID]
+	if len(changes) == 0 {
+		return
+	}
+	sb.WriteString("## Changes\n\n")
+	for _, ch := range changes {
+		line := ch.text
+		if ch.other != nil {
+			line += " " + c.refLink(*ch.other)
+		}
+		if ch.text == "Added" && c.Diff.OldCommit != "" {
+			line += " since " + shortCommit(c.Diff.OldCommit)
+		}
+		sb.WriteString(fmt.Sprintf("- %s\n", line))
+	}
+	sb.WriteString("\n")
+}
+
+// RenderChangelog returns a page summarizing Diff: added, removed and moved
+// entities, domain changes, and added and removed imports and calls.
+func (r *Renderer) RenderChangelog() Page {
+	c := r.siteContext()
+	d := r.Diff
+	if d == nil {
+		d = &GraphDiff{}
+	}
+	var sb strings.Builder
+
+	title := fmt.Sprintf("Changelog — %s", c.RepoName)
+	desc := fmt.Sprintf("Architecture changes in the %s codebase", c.RepoName)
+	if d.OldCommit != "" && d.NewCommit != "" {
+		desc += fmt.Sprintf(" between %s and %s", shortCommit(d.OldCommit), shortCommit(d.NewCommit))
+	}
+	sb.WriteString("---\n")
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", desc+"."))
+	sb.WriteString("node_type: \"Changelog\"\n")
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+	if d.OldCommit != "" {
+		sb.WriteString(fmt.Sprintf("old_commit: %q\n", d.OldCommit))
+	}
+	c.writeProvenance(&sb)
+	sb.WriteString("---\n\n")
+
+	if d.Empty() {
+		sb.WriteString("No architectural changes.\n")
+		return Page{Slug: "changelog", Title: "Changelog", Content: sb.String()}
+	}
+
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString("| Change | Count |\n|--------|-------|\n")
+	for _, row := range []struct {
+		label string
+		n     int
+	}{
+		{"Added", len(d.Added)},
+		{"Removed", len(d.Removed)},
+		{"Moved files", len(d.Moved)},
+		{"Domain changes", len(d.Reassigned)},
+		{"New imports and calls", len(d.AddedEdges)},
+		{"Removed imports and calls", len(d.RemovedEdges)},
+	} {
+		if row.n > 0 {
+			sb.WriteString(fmt.Sprintf("| %s | %d |\n", row.label, row.n))
+		}
+	}
+	sb.WriteString("\n")
+
+	c.writeRefList(&sb, "Added", d.Added)
+	c.writeRefList(&sb, "Removed", d.Removed)
+
+	if len(d.Moved) > 0 {
+		sb.WriteString("## Moved Files\n\n")
+		for _, m := range d.Moved {
+			sb.WriteString(fmt.Sprintf("- %s → %s\n", c.text(m.OldPath), c.refLink(m.EntityRef)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(d.Reassigned) > 0 {
+		sb.WriteString("## Domain Changes\n\n")
+		for _, re := range d.Reassigned {
+			from, to := joinDomain(re.OldDomain, re.OldSubdomain), joinDomain(re.NewDomain, re.NewSubdomain)
+			sb.WriteString(fmt.Sprintf("- %s: %s → %s\n", c.refLink(re.EntityRef), c.orNone(from), c.orNone(to)))
+		}
+		sb.WriteString("\n")
+	}
+
+	c.writeEdgeList(&sb, "New Imports", "IMPORTS", d.AddedEdges)
+	c.writeEdgeList(&sb, "New Calls", "calls", d.AddedEdges)
+	c.writeEdgeList(&sb, "Removed Imports", "IMPORTS", d.RemovedEdges)
+	c.writeEdgeList(&sb, "Removed Calls", "calls", d.RemovedEdges)
+
+	return Page{Slug: "changelog", Title: "Changelog", Content: sb.String()}
+}
+
+func (c *renderContext) writeRefList(sb *strings.Builder, heading string, refs []EntityRef) {
+	if len(refs) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("## %s\n\n", heading))
+	for _, ref := range refs {
+		detail := ref.Type
+		if ref.Type != "File" && ref.Type != "Directory" && ref.Path != "" {
+			detail += ", " + c.text(ref.Path)
+		}
+		sb.WriteString(fmt.Sprintf("- %s (%s)\n", c.refLink(ref), detail))
+	}
+	sb.WriteString("\n")
+}
+
+func (c *renderContext) writeEdgeList(sb *strings.Builder, heading, typ string, edges []EdgeChange) {
+	var lines []string
+	for _, e := range edges {
+		if e.Type == typ {
+			lines = append(lines, fmt.Sprintf("- %s → %s\n", c.refLink(e.From), c.refLink(e.To)))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("## %s\n\n", heading))
+	for _, l := range lines {
+		sb.WriteString(l)
+	}
+	sb.WriteString("\n")
+}
+
+// refLink links to a diffed entity's page, or names it if it has none
+// (such as a removed entity). Endpoints missing from both graphs are named
+// by ID.
+func (c *renderContext) refLink(ref EntityRef) string {
+	label := ref.Name
+	switch {
+	case ref.Name == "":
+		label = ref.ID
+	case ref.Type == "Function":
+		label += "()"
+	case ref.Type == "File" || ref.Type == "Directory":
+		label = cmp.Or(ref.Path, ref.Name)
+	}
+	return c.internalLink(ref.ID, label)
+}
+
+func (r *Renderer) orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return r.text(s)
+}
+
+func joinDomain(domain, subdomain string) string {
+	if subdomain == "" {
+		return domain
+	}
+	return domain + "/" + subdomain
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
@@ -0,0 +1,488 @@
+package graph2md
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// --- Graph Data (frontmatter) ---
+
+type graphNode struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Slug  string `json:"slug"`
+	Path  string `json:"path,omitempty"` // page path, outside the flat layout
+}
+
+type graphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+type graphData struct {
+	Nodes []graphNode `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+}
+
+func (c *renderContext) writeGraphData(sb *strings.Builder) {
+	var nodes []graphNode
+	var edges []graphEdge
+	seen := make(map[string]bool)
+
+	addNode := func(nodeID string) {
+		if seen[nodeID] || len(seen) >= 31 { // center + 30 neighbors
+			return
+		}
+		n := c.Nodes[nodeID]
+		if n == nil {
+			return
+		}
+		seen[nodeID] = true
+		label := getStr(n.Properties, "name")
+		if label == "" {
+			label = nodeID
+		}
+		nodeType := ""
+		if len(n.Labels) > 0 {
+			nodeType = n.Labels[0]
+		}
+		nodes = append(nodes, graphNode{
+			ID:    nodeID,
+			Label: label,
+			Type:  nodeType,
+			Slug:  c.Slugs[nodeID],
+			Path:  c.Paths[c.Slugs[nodeID]],
+		})
+	}
+
+	addEdge := func(from, to, relType string) {
+		edges = append(edges, graphEdge{Source: from, Target: to, Type: relType})
+	}
+
+	// Add center node
+	addNode(c.node.ID)
+
+	// Collect neighbor relationships
+	relSets := []struct {
+		ids     []string
+		relType string
+		reverse bool // if true, edge goes neighbor -> center
+	}{
+		{c.Imports[c.node.ID], "imports", false},
+		{c.ImportedBy[c.node.ID], "imports", true},
+		{c.Calls[c.node.ID], "calls", false},
+		{c.CalledBy[c.node.ID], "calls", true},
+		{c.DefinesFunc[c.node.ID], "defines", false},
+		{c.DeclaresClass[c.node.ID], "defines", false},
+		{c.DefinesType[c.node.ID], "defines", false},
+		{c.Extends[c.node.ID], "extends", false},
+		{c.ContainsFile[c.node.ID], "contains", false},
+		{c.ChildDir[c.node.ID], "contains", false},
+	}
+
+	// Add file-of reverse lookups
+	if fileID, ok := c.FileOfFunc[c.node.ID]; ok {
+		relSets = append(relSets, struct {
+			ids     []string
+			relType string
+			reverse bool
+		}{[]string{fileID}, "defines", true})
+	}
+	if fileID, ok := c.FileOfClass[c.node.ID]; ok {
+		relSets = append(relSets, struct {
+			ids     []string
+			relType string
+			reverse bool
+		}{[]string{fileID}, "defines", true})
+	}
+	if fileID, ok := c.FileOfType[c.node.ID]; ok {
+		relSets = append(relSets, struct {
+			ids     []string
+			relType string
+			reverse bool
+		}{[]string{fileID}, "defines", true})
+	}
+
+	// Domain/subdomain neighbors
+	if domNodeID, ok := c.BelongsToDomain[c.node.ID]; ok {
+		relSets = append(relSets, struct {
+			ids     []string
+			relType string
+			reverse bool
+		}{[]string{domNodeID}, "belongsTo", false})
+	}
+	if subNodeID, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+		relSets = append(relSets, struct {
+			ids     []string
+			relType string
+			reverse bool
+		}{[]string{subNodeID}, "belongsTo", false})
+	}
+
+	// For domains: add subdomain children
+	if c.label == "Domain" {
+		relSets = append(relSets, struct {
+			ids     []string
+			relType string
+			reverse bool
+		}{c.DomainSubdomains[c.node.ID], "contains", false})
+	}
+	// For subdomains: add domain parent
+	if c.label == "Subdomain" {
+		if domNodeID, ok := c.PartOfDomain[c.node.ID]; ok {
+			relSets = append(relSets, struct {
+				ids     []string
+				relType string
+				reverse bool
+			}{[]string{domNodeID}, "partOf", false})
+		}
+	}
+
+	for _, rs := range relSets {
+		for _, id := range rs.ids {
+			if len(seen) >= 31 {
+				break
+			}
+			addNode(id)
+			if !seen[id] {
+				continue // node wasn't added (cap reached before)
+			}
+			if rs.reverse {
+				addEdge(id, c.node.ID, rs.relType)
+			} else {
+				addEdge(c.node.ID, id, rs.relType)
+			}
+		}
+	}
+
+	if len(nodes) < 2 {
+		return // no neighbors, skip
+	}
+
+	gd := graphData{Nodes: nodes, Edges: edges}
+	data, err := json.Marshal(gd)
+	if err != nil {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("graph_data: %q\n", string(data)))
+}
+
+// --- Mermaid Diagram (frontmatter) ---
+
+func mermaidEscape(s string) string {
+	// Escape special chars for Mermaid node labels
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, `<`, "#lt;")
+	s = strings.ReplaceAll(s, `>`, "#gt;")
+	return s
+}
+
+func mermaidID(nodeID string) string {
+	// Create valid Mermaid node ID from arbitrary string
+	id := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, nodeID)
+	if id == "" {
+		id = "node"
+	}
+	return id
+}
+
+func (c *renderContext) writeMermaidDiagram(sb *strings.Builder) {
+	var lines []string
+	centerID := mermaidID(c.node.ID)
+	centerLabel := mermaidEscape(getStr(c.node.Properties, "name"))
+	if centerLabel == "" {
+		centerLabel = mermaidEscape(c.node.ID)
+	}
+	nodeCount := 0
+	maxNodes := 15
+
+	addedNodes := make(map[string]bool)
+
+	addNode := func(nodeID, label string) string {
+		mid := mermaidID(nodeID)
+		if !addedNodes[mid] {
+			addedNodes[mid] = true
+			nodeCount++
+		}
+		return mid
+	}
+
+	switch c.label {
+	case "File":
+		lines = append(lines, "graph LR")
+		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, centerLabel))
+		addedNodes[centerID] = true
+		nodeCount++
+
+		// Imports
+		for _, id := range c.Imports[c.node.ID] {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(id))
+			mid := addNode(id, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
+		}
+		// ImportedBy
+		for _, id := range c.ImportedBy[c.node.ID] {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(id))
+			mid := addNode(id, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s --> %s", mid, centerID))
+		}
+
+	case "Function":
+		lines = append(lines, "graph TD")
+		lines = append(lines, fmt.Sprintf("  %s[\"%s()\"]", centerID, centerLabel))
+		addedNodes[centerID] = true
+		nodeCount++
+
+		// File it's defined in
+		if fileID, ok := c.FileOfFunc[c.node.ID]; ok {
+			if nodeCount < maxNodes {
+				label := mermaidEscape(c.resolveName(fileID))
+				mid := addNode(fileID, label)
+				lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
+				lines = append(lines, fmt.Sprintf("  %s -->|defined in| %s", centerID, mid))
+			}
+		}
+
+		for _, id := range c.CalledBy[c.node.ID] {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(id))
+			mid := addNode(id, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s()\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s -->|calls| %s", mid, centerID))
+		}
+		for _, id := range c.Calls[c.node.ID] {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(id))
+			mid := addNode(id, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s()\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s -->|calls| %s", centerID, mid))
+		}
+
+	case "Type":
+		lines = append(lines, "graph TD")
+		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, centerLabel))
+		addedNodes[centerID] = true
+		nodeCount++
+
+		// File it's defined in
+		if fileID, ok := c.FileOfType[c.node.ID]; ok {
+			if nodeCount < maxNodes {
+				label := mermaidEscape(c.resolveName(fileID))
+				mid := addNode(fileID, label)
+				lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
+				lines = append(lines, fmt.Sprintf("  %s -->|defined in| %s", centerID, mid))
+			}
+		}
+
+	case "Class":
+		lines = append(lines, "graph TD")
+		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, centerLabel))
+		addedNodes[centerID] = true
+		nodeCount++
+
+		// Parent classes (extends)
+		for _, id := range c.Extends[c.node.ID] {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(id))
+			mid := addNode(id, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s -->|extends| %s", centerID, mid))
+		}
+
+		// File it's defined in
+		if fileID, ok := c.FileOfClass[c.node.ID]; ok {
+			if nodeCount < maxNodes {
+				label := mermaidEscape(c.resolveName(fileID))
+				mid := addNode(fileID, label)
+				lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
+				lines = append(lines, fmt.Sprintf("  %s -->|defined in| %s", centerID, mid))
+			}
+		}
+
+		// Methods defined on this class
+		for _, id := range c.DefinesFunc[c.node.ID] {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(id))
+			mid := addNode(id, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s()\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s -->|method| %s", centerID, mid))
+		}
+
+	case "Domain":
+		lines = append(lines, "graph TD")
+		domName := getStr(c.node.Properties, "name")
+		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, mermaidEscape(domName)))
+		addedNodes[centerID] = true
+		nodeCount++
+
+		for _, subID := range c.DomainSubdomains[c.node.ID] {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(subID))
+			mid := addNode(subID, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
+		}
+
+	case "Subdomain":
+		lines = append(lines, "graph TD")
+		subName := getStr(c.node.Properties, "name")
+		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", centerID, mermaidEscape(subName)))
+		addedNodes[centerID] = true
+		nodeCount++
+
+		files := c.SubdomainFiles[c.node.ID]
+		for _, fID := range files {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(fID))
+			mid := addNode(fID, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
+		}
+
+	case "Directory":
+		lines = append(lines, "graph TD")
+		dirName := getStr(c.node.Properties, "name")
+		if dirName == "" {
+			dirName = filepath.Base(getStr(c.node.Properties, "path"))
+		}
+		lines = append(lines, fmt.Sprintf("  %s[\"%s/\"]", centerID, mermaidEscape(dirName)))
+		addedNodes[centerID] = true
+		nodeCount++
+
+		for _, id := range c.ChildDir[c.node.ID] {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(id))
+			mid := addNode(id, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s/\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
+		}
+		for _, id := range c.ContainsFile[c.node.ID] {
+			if nodeCount >= maxNodes {
+				break
+			}
+			label := mermaidEscape(c.resolveName(id))
+			mid := addNode(id, label)
+			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mid, label))
+			lines = append(lines, fmt.Sprintf("  %s --> %s", centerID, mid))
+		}
+
+	default:
+		return
+	}
+
+	// Style the center node
+	if len(lines) > 1 && c.label != "Class" {
+		lines = append(lines, fmt.Sprintf("  style %s fill:#6366f1,stroke:#818cf8,color:#fff", centerID))
+	}
+
+	if nodeCount < 2 {
+		return
+	}
+
+	diagram := strings.Join(lines, "\n")
+	sb.WriteString(fmt.Sprintf("mermaid_diagram: %q\n", diagram))
+}
+
+// --- Architecture Map (frontmatter) ---
+
+func (c *renderContext) writeArchMap(sb *strings.Builder) {
+	archMap := make(map[string]interface{})
+
+	// Domain
+	if domNodeID := c.BelongsToDomain[c.node.ID]; c.NodeName(domNodeID) != "" {
+		entry := map[string]string{"name": c.NodeName(domNodeID)}
+		c.setArchSlug(entry, c.Slugs[domNodeID])
+		archMap["domain"] = entry
+	}
+
+	// Subdomain
+	if subNodeID := c.BelongsToSubdomain[c.node.ID]; c.NodeName(subNodeID) != "" {
+		entry := map[string]string{"name": c.NodeName(subNodeID)}
+		c.setArchSlug(entry, c.Slugs[subNodeID])
+		archMap["subdomain"] = entry
+	}
+
+	// File (for functions/classes/types)
+	switch c.label {
+	case "Function":
+		if fileID, ok := c.FileOfFunc[c.node.ID]; ok {
+			entry := map[string]string{"name": c.resolveName(fileID)}
+			c.setArchSlug(entry, c.Slugs[fileID])
+			archMap["file"] = entry
+		}
+	case "Class":
+		if fileID, ok := c.FileOfClass[c.node.ID]; ok {
+			entry := map[string]string{"name": c.resolveName(fileID)}
+			c.setArchSlug(entry, c.Slugs[fileID])
+			archMap["file"] = entry
+		}
+	case "Type":
+		if fileID, ok := c.FileOfType[c.node.ID]; ok {
+			entry := map[string]string{"name": c.resolveName(fileID)}
+			c.setArchSlug(entry, c.Slugs[fileID])
+			archMap["file"] = entry
+		}
+	}
+
+	// Entity itself
+	name := getStr(c.node.Properties, "name")
+	if name == "" {
+		name = c.node.ID
+	}
+	entity := map[string]string{
+		"name": name,
+		"type": c.label,
+	}
+	c.setArchSlug(entity, c.slug)
+	archMap["entity"] = entity
+
+	if len(archMap) < 2 {
+		return // just the entity itself, not useful
+	}
+
+	data, err := json.Marshal(archMap)
+	if err != nil {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("arch_map: %q\n", string(data)))
+}
+
+// setArchSlug records slug in an arch_map entry, along with the page path
+// when the layout nests pages.
+func (c *renderContext) setArchSlug(entry map[string]string, slug string) {
+	entry["slug"] = slug
+	if p, ok := c.Paths[slug]; ok {
+		entry["path"] = p
+	}
+}
@@ -0,0 +1,326 @@
+package graph2md
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// EntityRef identifies an entity in a GraphDiff. ID is the entity's ID in
+// the new graph, or in the old graph if it no longer exists.
+type EntityRef struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+}
+
+// FileMove is a file whose path changed between snapshots.
+type FileMove struct {
+	EntityRef
+	OldPath string `json:"old_path"`
+}
+
+// Reassignment is an entity whose domain or subdomain changed.
+type Reassignment struct {
+	EntityRef
+	OldDomain    string `json:"old_domain,omitempty"`
+	NewDomain    string `json:"new_domain,omitempty"`
+	OldSubdomain string `json:"old_subdomain,omitempty"`
+	NewSubdomain string `json:"new_subdomain,omitempty"`
+}
+
+// EdgeChange is an import or call relationship added or removed.
+type EdgeChange struct {
+	Type string    `json:"type"`
+	From EntityRef `json:"from"`
+	To   EntityRef `json:"to"`
+}
+
+// GraphDiff describes what changed between two graph snapshots.
+type GraphDiff struct {
+	OldCommit    string         `json:"old_commit,omitempty"`
+	NewCommit    string         `json:"new_commit,omitempty"`
+	Added        []EntityRef    `json:"added"`
+	Removed      []EntityRef    `json:"removed"`
+	Moved        []FileMove     `json:"moved"`
+	Reassigned   []Reassignment `json:"reassigned"`
+	AddedEdges   []EdgeChange   `json:"added_edges"`
+	RemovedEdges []EdgeChange   `json:"removed_edges"`
+}
+
+// diffEdgeTypes are the relationship types compared by DiffGraphs.
+var diffEdgeTypes = []string{"IMPORTS", "calls"}
+
+// Empty reports whether the snapshots have no differences.
+func (d *GraphDiff) Empty() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Moved)+len(d.Reassigned)+len(d.AddedEdges)+len(d.RemovedEdges) == 0
+}
+
+// DiffGraphs compares two snapshots of a graph. Entities are matched by
+// node ID, then by type, path and name, so snapshots whose IDs aren't
+// stable still compare cleanly. A file whose path changed is reported as
+// moved when it keeps its ID, or keeps its name and defined functions.
+func DiffGraphs(old, new *GraphResult) *GraphDiff {
+	oldIdx := BuildIndex(old.Graph.Nodes, old.Graph.Relationships)
+	newIdx := BuildIndex(new.Graph.Nodes, new.Graph.Relationships)
+	d := &GraphDiff{
+		OldCommit:    old.Commit(),
+		NewCommit:    new.Commit(),
+		Added:        []EntityRef{},
+		Removed:      []EntityRef{},
+		Moved:        []FileMove{},
+		Reassigned:   []Reassignment{},
+		AddedEdges:   []EdgeChange{},
+		RemovedEdges: []EdgeChange{},
+	}
+
+	match := make(map[string]string) // old ID -> new ID
+	matched := make(map[string]bool) // new IDs matched
+	moves := make(map[string]string) // old file path -> new file path
+	pair := func(oldID, newID string) {
+		match[oldID] = newID
+		matched[newID] = true
+	}
+
+	for _, n := range old.Graph.Nodes {
+		if nn, ok := newIdx.Nodes[n.ID]; ok && nn.PrimaryLabel() == n.PrimaryLabel() {
+			pair(n.ID, n.ID)
+		}
+	}
+
+	// Files: by path, then by name and defined functions.
+	unmatchedFiles := func(g *GraphResult, done func(string) bool) []*Node {
+		var files []*Node
+		for i := range g.Graph.Nodes {
+			if n := &g.Graph.Nodes[i]; n.PrimaryLabel() == "File" && !done(n.ID) {
+				files = append(files, n)
+			}
+		}
+		return files
+	}
+	isMatched := func(id string) bool { _, ok := match[id]; return ok }
+	newFiles := unmatchedFiles(new, func(id string) bool { return matched[id] })
+	newByPath := make(map[string]string)
+	for _, n := range newFiles {
+		newByPath[getStr(n.Properties, "path")] = n.ID
+	}
+	for _, n := range unmatchedFiles(old, isMatched) {
+		if id, ok := newByPath[getStr(n.Properties, "path")]; ok && !matched[id] {
+			pair(n.ID, id)
+		}
+	}
+	newBySig := make(map[string][]string)
+	for _, n := range unmatchedFiles(new, func(id string) bool { return matched[id] }) {
+		if sig := fileSignature(n, newIdx); sig != "" {
+			newBySig[sig] = append(newBySig[sig], n.ID)
+		}
+	}
+	for _, n := range unmatchedFiles(old, isMatched) {
+		sig := fileSignature(n, oldIdx)
+		if ids := newBySig[sig]; sig != "" && len(ids) == 1 && !matched[ids[0]] {
+			pair(n.ID, ids[0])
+		}
+	}
+	for _, n := range old.Graph.Nodes {
+		if n.PrimaryLabel() != "File" {
+			continue
+		}
+		if id, ok := match[n.ID]; ok {
+			oldPath, newPath := getStr(n.Properties, "path"), getStr(newIdx.Nodes[id].Properties, "path")
+			if oldPath != newPath {
+				moves[oldPath] = newPath
+				d.Moved = append(d.Moved, FileMove{EntityRef: entityRef(newIdx.Nodes[id]), OldPath: oldPath})
+			}
+		}
+	}
+
+	// Everything else: by type, (moved) path and name.
+	newByKey := make(map[string]string)
+	for i := range new.Graph.Nodes {
+		if n := &new.Graph.Nodes[i]; !matched[n.ID] {
+			newByKey[entityKey(n, nil)] = n.ID
+		}
+	}
+	for i := range old.Graph.Nodes {
+		n := &old.Graph.Nodes[i]
+		if isMatched(n.ID) {
+			continue
+		}
+		if id, ok := newByKey[entityKey(n, moves)]; ok && !matched[id] {
+			pair(n.ID, id)
+		}
+	}
+
+	for i := range old.Graph.Nodes {
+		if n := &old.Graph.Nodes[i]; !isMatched(n.ID) && GenerateLabels[n.PrimaryLabel()] {
+			d.Removed = append(d.Removed, entityRef(n))
+		}
+	}
+	for i := range new.Graph.Nodes {
+		if n := &new.Graph.Nodes[i]; !matched[n.ID] && GenerateLabels[n.PrimaryLabel()] {
+			d.Added = append(d.Added, entityRef(n))
+		}
+	}
+
+	for oldID, newID := range match {
+		n := newIdx.Nodes[newID]
+		switch n.PrimaryLabel() {
+		case "File", "Function", "Class", "Type":
+		default:
+			continue
+		}
+		r := Reassignment{
+			EntityRef:    entityRef(n),
+			OldDomain:    oldIdx.NodeName(oldIdx.BelongsToDomain[oldID]),
+			NewDomain:    newIdx.NodeName(newIdx.BelongsToDomain[newID]),
+			OldSubdomain: oldIdx.NodeName(oldIdx.BelongsToSubdomain[oldID]),
+			NewSubdomain: newIdx.NodeName(newIdx.BelongsToSubdomain[newID]),
+		}
+		if r.OldDomain != r.NewDomain || r.OldSubdomain != r.NewSubdomain {
+			d.Reassigned = append(d.Reassigned, r)
+		}
+	}
+
+	// Edges are compared with old endpoints translated to new IDs; old
+	// nodes without a match keep a prefixed old ID. Endpoints that aren't
+	// nodes of the old graph keep their ID, so an unchanged edge to a
+	// missing node compares equal.
+	const oldPrefix = "\x00old\x00"
+	ref := func(id string) EntityRef {
+		if n, ok := newIdx.Nodes[id]; ok {
+			return entityRef(n)
+		}
+		if n, ok := oldIdx.Nodes[id]; ok {
+			return entityRef(n)
+		}
+		return EntityRef{ID: id}
+	}
+	translate := func(id string) string {
+		if newID, ok := match[id]; ok {
+			return newID
+		}
+		if _, ok := oldIdx.Nodes[id]; ok {
+			return oldPrefix + id
+		}
+		return id
+	}
+	edgeSet := func(rels []Relationship, tr func(string) string) map[[3]string]bool {
+		set := make(map[[3]string]bool)
+		for _, rel := range rels {
+			if slices.Contains(diffEdgeTypes, rel.Type) {
+				set[[3]string{rel.Type, tr(rel.StartNode), tr(rel.EndNode)}] = true
+			}
+		}
+		return set
+	}
+	oldEdges := edgeSet(old.Graph.Relationships, translate)
+	newEdges := edgeSet(new.Graph.Relationships, func(id string) string { return id })
+	untranslate := func(id string) string {
+		id, _ = strings.CutPrefix(id, oldPrefix)
+		return id
+	}
+	for e := range newEdges {
+		if !oldEdges[e] {
+			d.AddedEdges = append(d.AddedEdges, EdgeChange{Type: e[0], From: ref(e[1]), To: ref(e[2])})
+		}
+	}
+	for e := range oldEdges {
+		if !newEdges[e] {
+			d.RemovedEdges = append(d.RemovedEdges, EdgeChange{Type: e[0], From: ref(untranslate(e[1])), To: ref(untranslate(e[2]))})
+		}
+	}
+
+	sortRefs(d.Added)
+	sortRefs(d.Removed)
+	sort.Slice(d.Moved, func(i, j int) bool { return refLess(d.Moved[i].EntityRef, d.Moved[j].EntityRef) })
+	sort.Slice(d.Reassigned, func(i, j int) bool { return refLess(d.Reassigned[i].EntityRef, d.Reassigned[j].EntityRef) })
+	sortEdges(d.AddedEdges)
+	sortEdges(d.RemovedEdges)
+	return d
+}
+
+// fileSignature identifies a file by its name and the functions it
+// defines, for recognizing a file that moved. Files without functions have
+// no signature.
+func fileSignature(n *Node, idx *Index) string {
+	var names []string
+	for _, id := range idx.DefinesFunc[n.ID] {
+		if fn, ok := idx.Nodes[id]; ok {
+			names = append(names, getStr(fn.Properties, "name"))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	sig := filepath.Base(getStr(n.Properties, "path"))
+	for _, name := range names {
+		sig += "\x00" + name
+	}
+	return sig
+}
+
+// entityKey identifies an entity by type, path and name. File paths found
+// in moves are replaced by the file's new path.
+func entityKey(n *Node, moves map[string]string) string {
+	props := n.Properties
+	label := n.PrimaryLabel()
+	switch label {
+	case "File", "Directory":
+		return label + "\x00" + getStr(props, "path")
+	case "Domain", "Subdomain":
+		return label + "\x00" + getStr(props, "name")
+	}
+	filePath := getStr(props, "filePath")
+	if p, ok := moves[filePath]; ok {
+		filePath = p
+	}
+	return label + "\x00" + filePath + "\x00" + getStr(props, "name")
+}
+
+func entityRef(n *Node) EntityRef {
+	props := n.Properties
+	ref := EntityRef{ID: n.ID, Type: n.PrimaryLabel(), Name: getStr(props, "name"), Path: getStr(props, "filePath")}
+	if p := getStr(props, "path"); p != "" {
+		ref.Path = p
+		if ref.Name == "" {
+			ref.Name = filepath.Base(p)
+		}
+	}
+	if ref.Name == "" {
+		ref.Name = n.ID
+	}
+	return ref
+}
+
+func refLess(a, b EntityRef) bool {
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.ID < b.ID
+}
+
+func sortRefs(refs []EntityRef) {
+	sort.Slice(refs, func(i, j int) bool { return refLess(refs[i], refs[j]) })
+}
+
+func sortEdges(edges []EdgeChange) {
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.From != b.From {
+			return refLess(a.From, b.From)
+		}
+		return refLess(a.To, b.To)
+	})
+}
@@ -0,0 +1,123 @@
+package graph2md
+
+import (
+	"strings"
+	"testing"
+)
+
+// testNode returns a node with one label and the given property name/value
+// pairs.
+func testNode(id, label string, props ...string) Node {
+	n := Node{ID: id, Labels: []string{label}, Properties: make(map[string]interface{})}
+	for i := 0; i+1 < len(props); i += 2 {
+		n.Properties[props[i]] = props[i+1]
+	}
+	return n
+}
+
+func testRel(id, typ, start, end string) Relationship {
+	return Relationship{ID: id, Type: typ, StartNode: start, EndNode: end}
+}
+
+func refIDs(refs []EntityRef) []string {
+	ids := make([]string, len(refs))
+	for i, r := range refs {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func edgeStrings(edges []EdgeChange) []string {
+	s := make([]string, len(edges))
+	for i, e := range edges {
+		s[i] = e.Type + " " + e.From.ID + "->" + e.To.ID
+	}
+	return s
+}
+
+func TestDiffGraphs(t *testing.T) {
+	old := &GraphResult{Graph: Graph{
+		Nodes: []Node{
+			testNode("f1", "File", "path", "src/auth/store.ts", "name", "store.ts"),
+			testNode("f2", "File", "path", "src/app.ts", "name", "app.ts"),
+			testNode("fn1", "Function", "name", "save", "filePath", "src/auth/store.ts"),
+			testNode("fn2", "Function", "name", "main", "filePath", "src/app.ts"),
+			testNode("fn3", "Function", "name", "gone", "filePath", "src/app.ts"),
+		},
+		Relationships: []Relationship{
+			testRel("r1", "DEFINES_FUNCTION", "f1", "fn1"),
+			testRel("r2", "DEFINES_FUNCTION", "f2", "fn2"),
+			testRel("r3", "DEFINES_FUNCTION", "f2", "fn3"),
+			testRel("r4", "calls", "fn2", "fn1"),
+			testRel("r5", "calls", "fn2", "missing"),
+			testRel("r6", "calls", "fn2", "fn3"),
+		},
+	}}
+	// The file moved to src/session and every ID was regenerated; fn3 was
+	// deleted and fn4 added.
+	new := &GraphResult{Graph: Graph{
+		Nodes: []Node{
+			testNode("F1", "File", "path", "src/session/store.ts", "name", "store.ts"),
+			testNode("F2", "File", "path", "src/app.ts", "name", "app.ts"),
+			testNode("FN1", "Function", "name", "save", "filePath", "src/session/store.ts"),
+			testNode("FN2", "Function", "name", "main", "filePath", "src/app.ts"),
+			testNode("FN4", "Function", "name", "start", "filePath", "src/app.ts"),
+		},
+		Relationships: []Relationship{
+			testRel("R1", "DEFINES_FUNCTION", "F1", "FN1"),
+			testRel("R2", "DEFINES_FUNCTION", "F2", "FN2"),
+			testRel("R3", "DEFINES_FUNCTION", "F2", "FN4"),
+			testRel("R4", "calls", "FN2", "FN1"),
+			testRel("R5", "calls", "FN2", "missing"),
+			testRel("R6", "calls", "FN4", "other-missing"),
+		},
+	}}
+
+	d := DiffGraphs(old, new)
+
+	if got := refIDs(d.Added); strings.Join(got, ",") != "FN4" {
+		t.Errorf("Added = %v, want [FN4]", got)
+	}
+	if got := refIDs(d.Removed); strings.Join(got, ",") != "fn3" {
+		t.Errorf("Removed = %v, want [fn3]", got)
+	}
+	if len(d.Moved) != 1 || d.Moved[0].ID != "F1" || d.Moved[0].OldPath != "src/auth/store.ts" || d.Moved[0].Path != "src/session/store.ts" {
+		t.Errorf("Moved = %+v, want F1 from src/auth/store.ts to src/session/store.ts", d.Moved)
+	}
+	if len(d.Reassigned) != 0 {
+		t.Errorf("Reassigned = %+v, want none", d.Reassigned)
+	}
+	// Matched endpoints are reported by new ID. The unchanged calls edges,
+	// including the one to a node missing from both graphs, compare equal
+	// across the re-keyed IDs.
+	if got, want := strings.Join(edgeStrings(d.AddedEdges), ", "), "calls FN4->other-missing"; got != want {
+		t.Errorf("AddedEdges = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(edgeStrings(d.RemovedEdges), ", "), "calls FN2->fn3"; got != want {
+		t.Errorf("RemovedEdges = %s, want %s", got, want)
+	}
+
+	_, slugs := AssignSlugs(new.Graph.Nodes)
+	r := &Renderer{Index: BuildIndex(new.Graph.Nodes, new.Graph.Relationships), Slugs: slugs, MarkdownLinks: true, Diff: d}
+	page := r.RenderChangelog()
+	if !strings.Contains(page.Content, "→ other-missing\n") {
+		t.Errorf("changelog doesn't name the missing call target by ID:\n%s", page.Content)
+	}
+}
+
+func TestDiffGraphsUnchanged(t *testing.T) {
+	g := &GraphResult{Graph: Graph{
+		Nodes: []Node{
+			testNode("f1", "File", "path", "a.ts"),
+			testNode("fn1", "Function", "name", "run", "filePath", "a.ts"),
+		},
+		Relationships: []Relationship{
+			testRel("r1", "DEFINES_FUNCTION", "f1", "fn1"),
+			testRel("r2", "calls", "fn1", "missing"),
+			testRel("r3", "IMPORTS", "f1", "missing-file"),
+		},
+	}}
+	if d := DiffGraphs(g, g); !d.Empty() {
+		t.Errorf("diff of a graph with itself = %+v, want empty", d)
+	}
+}
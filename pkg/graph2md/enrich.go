@@ -0,0 +1,187 @@
+package graph2md
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Enrichment is hand-written or generated content for one entity page,
+// loaded from a JSON sidecar file. It survives regeneration: its fields
+// replace or extend the templated text graph2md derives from the graph.
+type Enrichment struct {
+	ID          string                 `json:"id"`          // node ID this sidecar applies to
+	Slug        string                 `json:"slug"`        // page slug, used when ID is empty
+	Description string                 `json:"description"` // replaces the generated description
+	Summary     string                 `json:"summary"`     // replaces or adds the summary field
+	Tags        []string               `json:"tags"`        // appended to the generated tags
+	FAQs        []FAQ                  `json:"faqs"`        // appended to the generated FAQs
+	Frontmatter map[string]interface{} `json:"frontmatter"` // extra or overriding frontmatter fields
+}
+
+// FAQ is a single question and answer pair.
+type FAQ struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// frontmatterKey matches the frontmatter keys a sidecar may set; they are
+// written into the page unquoted.
+var frontmatterKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reservedKeys are the frontmatter fields graph2md uses to recognize and
+// track its pages, which sidecars may not override.
+var reservedKeys = map[string]bool{
+	"generator": true, "commit": true, "generated_at": true, "slug": true, "aliases": true,
+}
+
+// LoadEnrichments reads every *.json sidecar in dir. Each sidecar is keyed by
+// its "id" field, else its "slug" field, else its file name without the .json
+// extension, so a file named <slug>.json needs no key of its own. A missing
+// directory yields no enrichments. A sidecar whose frontmatter has a key that
+// isn't letters, digits, "-" and "_", or a reserved one, is an error.
+func LoadEnrichments(dir string) (map[string]*Enrichment, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	enrichments := make(map[string]*Enrichment)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var e Enrichment
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := e.checkFrontmatter(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		key := e.ID
+		if key == "" {
+			key = e.Slug
+		}
+		if key == "" {
+			key = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		enrichments[key] = &e
+	}
+	return enrichments, nil
+}
+
+// checkFrontmatter rejects frontmatter keys that can't be written as is or
+// that graph2md reserves.
+func (e *Enrichment) checkFrontmatter() error {
+	keys := make([]string, 0, len(e.Frontmatter))
+	for k := range e.Frontmatter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !frontmatterKey.MatchString(k) {
+			return fmt.Errorf("invalid frontmatter key %q (want letters, digits, - and _)", k)
+		}
+		if reservedKeys[k] {
+			return fmt.Errorf("frontmatter key %q is reserved for graph2md", k)
+		}
+	}
+	return nil
+}
+
+// enrichmentFor returns the sidecar for a node, looked up by node ID first
+// and then by slug.
+func (r *Renderer) enrichmentFor(nodeID, slug string) *Enrichment {
+	if e, ok := r.Enrichments[nodeID]; ok {
+		return e
+	}
+	return r.Enrichments[slug]
+}
+
+// applyEnrichment rewrites generated frontmatter with the enrichment's
+// description, summary and extra fields. Overridden keys are replaced in
+// place (dropping any indented continuation lines); new keys are appended.
+func (c *renderContext) applyEnrichment(fm string) string {
+	e := c.enrichment
+	if e == nil {
+		return fm
+	}
+
+	overrides := make(map[string]string)
+	var keys []string
+	set := func(key, value string) {
+		if _, ok := overrides[key]; !ok {
+			keys = append(keys, key)
+		}
+		overrides[key] = value
+	}
+	if e.Description != "" {
+		set("description", fmt.Sprintf("%q", e.Description))
+	}
+	if e.Summary != "" {
+		set("summary", fmt.Sprintf("%q", e.Summary))
+	}
+	extra := make([]string, 0, len(e.Frontmatter))
+	for k := range e.Frontmatter {
+		extra = append(extra, k)
+	}
+	sort.Strings(extra)
+	for _, k := range extra {
+		// LoadEnrichments rejects these; sidecars built in code may not.
+		if !frontmatterKey.MatchString(k) || reservedKeys[k] {
+			continue
+		}
+		set(k, yamlValue(e.Frontmatter[k]))
+	}
+	if len(keys) == 0 {
+		return fm
+	}
+
+	var sb strings.Builder
+	written := make(map[string]bool)
+	skipping := false
+	for _, line := range strings.SplitAfter(fm, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, " ") {
+			if !skipping {
+				sb.WriteString(line)
+			}
+			continue
+		}
+		key, _, _ := strings.Cut(line, ":")
+		value, ok := overrides[key]
+		skipping = ok
+		if !ok {
+			sb.WriteString(line)
+		} else if !written[key] {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", key, value))
+			written[key] = true
+		}
+	}
+	for _, k := range keys {
+		if !written[k] {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", k, overrides[k]))
+		}
+	}
+	return sb.String()
+}
+
+// yamlValue formats a decoded JSON value as a YAML scalar. Strings are
+// quoted like the rest of the frontmatter; everything else is written as
+// JSON, which YAML accepts as flow syntax.
+func yamlValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
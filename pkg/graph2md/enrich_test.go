@@ -0,0 +1,93 @@
+package graph2md
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadEnrichments(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"by-id.json":       `{"id": "fn1", "summary": "By ID."}`,
+		"by-slug.json":     `{"slug": "fn-login", "summary": "By slug."}`,
+		"fn-save.json":     `{"summary": "By file name.", "frontmatter": {"weight": 10, "nav_title": "Save"}}`,
+		"notes.txt":        `not a sidecar`,
+		"nested/deep.json": `{"id": "ignored"}`,
+	})
+	enrichments, err := LoadEnrichments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, summary := range map[string]string{"fn1": "By ID.", "fn-login": "By slug.", "fn-save": "By file name."} {
+		if e := enrichments[key]; e == nil || e.Summary != summary {
+			t.Errorf("enrichment %q = %+v, want summary %q", key, e, summary)
+		}
+	}
+	if len(enrichments) != 3 {
+		t.Errorf("loaded %d enrichments, want 3", len(enrichments))
+	}
+
+	if enrichments, err := LoadEnrichments(filepath.Join(dir, "missing")); err != nil || len(enrichments) != 0 {
+		t.Errorf("LoadEnrichments(missing dir) = %v, %v; want nothing", enrichments, err)
+	}
+}
+
+func TestLoadEnrichmentsRejectsKeys(t *testing.T) {
+	for _, tt := range []struct {
+		frontmatter string
+		wantErr     string
+	}{
+		{`{"bad key": 1}`, "invalid frontmatter key"},
+		{`{"x: 1\ninjected": 1}`, "invalid frontmatter key"},
+		{`{"": 1}`, "invalid frontmatter key"},
+		{`{"title": "ok", "generator": "me"}`, `"generator" is reserved`},
+		{`{"commit": "abc"}`, `"commit" is reserved`},
+		{`{"slug": "other"}`, `"slug" is reserved`},
+		{`not json`, "invalid character"},
+	} {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"page.json": `{"frontmatter": ` + tt.frontmatter + `}`})
+		_, err := LoadEnrichments(dir)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "page.json") {
+			t.Errorf("frontmatter %s: error = %v, want %q naming the file", tt.frontmatter, err, tt.wantErr)
+		}
+	}
+}
+
+func TestApplyEnrichment(t *testing.T) {
+	fm := "title: \"login\"\n" +
+		"description: \"Generated.\"\n" +
+		"tags:\n  - \"Function\"\n" +
+		generatorMarker + "\n" +
+		"commit: \"abc\"\n"
+	c := &renderContext{enrichment: &Enrichment{
+		Description: `Says "hi".`,
+		Summary:     "Short.",
+		Frontmatter: map[string]interface{}{
+			"tags":      []interface{}{"custom"},
+			"weight":    10.0,
+			"draft":     false,
+			"commit":    "forged", // reserved: ignored
+			"bad key":   "x",      // invalid: ignored
+			"nav_title": "Login",
+		},
+	}}
+	want := "title: \"login\"\n" +
+		"description: \"Says \\\"hi\\\".\"\n" +
+		"tags: [\"custom\"]\n" +
+		generatorMarker + "\n" +
+		"commit: \"abc\"\n" +
+		"summary: \"Short.\"\n" +
+		"draft: false\n" +
+		"nav_title: \"Login\"\n" +
+		"weight: 10\n"
+	if got := c.applyEnrichment(fm); got != want {
+		t.Errorf("applyEnrichment =\n%s\nwant\n%s", got, want)
+	}
+
+	c.enrichment = nil
+	if got := c.applyEnrichment(fm); got != fm {
+		t.Errorf("applyEnrichment without a sidecar changed the frontmatter:\n%s", got)
+	}
+}
@@ -0,0 +1,328 @@
+package graph2md
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// --- FAQ Section ---
+
+func (c *renderContext) writeFAQSection(sb *strings.Builder) {
+	type faqEntry struct{ q, a string }
+	var faqs []faqEntry
+
+	name := getStr(c.node.Properties, "name")
+	if name == "" {
+		name = c.node.ID
+	}
+
+	switch c.label {
+	case "File":
+		path := getStr(c.node.Properties, "path")
+		fileName := getStr(c.node.Properties, "name")
+		if fileName == "" {
+			fileName = filepath.Base(path)
+		}
+		lang := getStr(c.node.Properties, "language")
+
+		// What does file do?
+		desc := fmt.Sprintf("%s is a source file in the %s codebase", fileName, c.RepoName)
+		if lang != "" {
+			desc += fmt.Sprintf(", written in %s", lang)
+		}
+		desc += "."
+		if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+			desc += fmt.Sprintf(" It belongs to the %s domain", c.NodeName(d))
+			if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+				desc += fmt.Sprintf(", %s subdomain", c.NodeName(s))
+			}
+			desc += "."
+		}
+		faqs = append(faqs, faqEntry{fmt.Sprintf("What does %s do?", fileName), desc})
+
+		// Functions defined
+		funcs := c.DefinesFunc[c.node.ID]
+		if len(funcs) > 0 {
+			names := c.resolveNames(funcs)
+			sort.Strings(names)
+			listed := names
+			if len(listed) > 10 {
+				listed = listed[:10]
+			}
+			a := fmt.Sprintf("%s defines %d function(s): %s", fileName, len(funcs), strings.Join(listed, ", "))
+			if len(funcs) > 10 {
+				a += fmt.Sprintf(", and %d more", len(funcs)-10)
+			}
+			a += "."
+			faqs = append(faqs, faqEntry{fmt.Sprintf("What functions are defined in %s?", fileName), a})
+		}
+
+		// Dependencies
+		deps := c.Imports[c.node.ID]
+		if len(deps) > 0 {
+			names := c.resolveNames(deps)
+			sort.Strings(names)
+			listed := names
+			if len(listed) > 8 {
+				listed = listed[:8]
+			}
+			a := fmt.Sprintf("%s imports %d module(s): %s", fileName, len(deps), strings.Join(listed, ", "))
+			if len(deps) > 8 {
+				a += fmt.Sprintf(", and %d more", len(deps)-8)
+			}
+			a += "."
+			faqs = append(faqs, faqEntry{fmt.Sprintf("What does %s depend on?", fileName), a})
+		}
+
+		// Imported by
+		ib := c.ImportedBy[c.node.ID]
+		if len(ib) > 0 {
+			names := c.resolveNames(ib)
+			sort.Strings(names)
+			listed := names
+			if len(listed) > 8 {
+				listed = listed[:8]
+			}
+			a := fmt.Sprintf("%s is imported by %d file(s): %s", fileName, len(ib), strings.Join(listed, ", "))
+			if len(ib) > 8 {
+				a += fmt.Sprintf(", and %d more", len(ib)-8)
+			}
+			a += "."
+			faqs = append(faqs, faqEntry{fmt.Sprintf("What files import %s?", fileName), a})
+		}
+
+		// Architecture position
+		archParts := []string{}
+		if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+			archParts = append(archParts, fmt.Sprintf("domain: %s", c.NodeName(d)))
+		}
+		if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+			archParts = append(archParts, fmt.Sprintf("subdomain: %s", c.NodeName(s)))
+		}
+		dir := filepath.Dir(path)
+		if dir != "" && dir != "." {
+			archParts = append(archParts, fmt.Sprintf("directory: %s", dir))
+		}
+		if len(archParts) > 0 {
+			faqs = append(faqs, faqEntry{
+				fmt.Sprintf("Where is %s in the architecture?", fileName),
+				fmt.Sprintf("%s is located at %s (%s).", fileName, path, strings.Join(archParts, ", ")),
+			})
+		}
+
+	case "Function":
+		funcName := name + "()"
+
+		// What does it do?
+		desc := fmt.Sprintf("%s is a function in the %s codebase", funcName, c.RepoName)
+		if fileID, ok := c.FileOfFunc[c.node.ID]; ok {
+			desc += fmt.Sprintf(", defined in %s", c.resolveNameWithPath(fileID))
+		}
+		desc += "."
+		faqs = append(faqs, faqEntry{fmt.Sprintf("What does %s do?", funcName), desc})
+
+		// Where defined
+		if fileID, ok := c.FileOfFunc[c.node.ID]; ok {
+			filePath := c.resolveNameWithPath(fileID)
+			startLine := getNum(c.node.Properties, "startLine")
+			a := fmt.Sprintf("%s is defined in %s", funcName, filePath)
+			if startLine > 0 {
+				a += fmt.Sprintf(" at line %d", startLine)
+			}
+			a += "."
+			faqs = append(faqs, faqEntry{fmt.Sprintf("Where is %s defined?", funcName), a})
+		}
+
+		// What does it call?
+		called := c.Calls[c.node.ID]
+		if len(called) > 0 {
+			names := c.resolveNames(called)
+			sort.Strings(names)
+			listed := names
+			if len(listed) > 8 {
+				listed = listed[:8]
+			}
+			a := fmt.Sprintf("%s calls %d function(s): %s", funcName, len(called), strings.Join(listed, ", "))
+			if len(called) > 8 {
+				a += fmt.Sprintf(", and %d more", len(called)-8)
+			}
+			a += "."
+			faqs = append(faqs, faqEntry{fmt.Sprintf("What does %s call?", funcName), a})
+		}
+
+		// What calls it?
+		callers := c.CalledBy[c.node.ID]
+		if len(callers) > 0 {
+			names := c.resolveNames(callers)
+			sort.Strings(names)
+			listed := names
+			if len(listed) > 8 {
+				listed = listed[:8]
+			}
+			a := fmt.Sprintf("%s is called by %d function(s): %s", funcName, len(callers), strings.Join(listed, ", "))
+			if len(callers) > 8 {
+				a += fmt.Sprintf(", and %d more", len(callers)-8)
+			}
+			a += "."
+			faqs = append(faqs, faqEntry{fmt.Sprintf("What calls %s?", funcName), a})
+		}
+
+	case "Class":
+		className := name
+
+		desc := fmt.Sprintf("%s is a class in the %s codebase", className, c.RepoName)
+		if fileID, ok := c.FileOfClass[c.node.ID]; ok {
+			desc += fmt.Sprintf(", defined in %s", c.resolveNameWithPath(fileID))
+		}
+		desc += "."
+		faqs = append(faqs, faqEntry{fmt.Sprintf("What is the %s class?", className), desc})
+
+		if fileID, ok := c.FileOfClass[c.node.ID]; ok {
+			filePath := c.resolveNameWithPath(fileID)
+			startLine := getNum(c.node.Properties, "startLine")
+			a := fmt.Sprintf("%s is defined in %s", className, filePath)
+			if startLine > 0 {
+				a += fmt.Sprintf(" at line %d", startLine)
+			}
+			a += "."
+			faqs = append(faqs, faqEntry{fmt.Sprintf("Where is %s defined?", className), a})
+		}
+
+		extends := c.Extends[c.node.ID]
+		if len(extends) > 0 {
+			names := c.resolveNames(extends)
+			faqs = append(faqs, faqEntry{
+				fmt.Sprintf("What does %s extend?", className),
+				fmt.Sprintf("%s extends %s.", className, strings.Join(names, ", ")),
+			})
+		}
+
+	case "Type":
+		typeName := name
+
+		desc := fmt.Sprintf("%s is a type/interface in the %s codebase", typeName, c.RepoName)
+		if fileID, ok := c.FileOfType[c.node.ID]; ok {
+			desc += fmt.Sprintf(", defined in %s", c.resolveNameWithPath(fileID))
+		}
+		desc += "."
+		faqs = append(faqs, faqEntry{fmt.Sprintf("What is the %s type?", typeName), desc})
+
+		if fileID, ok := c.FileOfType[c.node.ID]; ok {
+			filePath := c.resolveNameWithPath(fileID)
+			startLine := getNum(c.node.Properties, "startLine")
+			a := fmt.Sprintf("%s is defined in %s", typeName, filePath)
+			if startLine > 0 {
+				a += fmt.Sprintf(" at line %d", startLine)
+			}
+			a += "."
+			faqs = append(faqs, faqEntry{fmt.Sprintf("Where is %s defined?", typeName), a})
+		}
+
+	case "Domain":
+		domainName := name
+		fileCount := len(c.DomainFiles[c.node.ID])
+		subs := c.DomainSubdomains[c.node.ID]
+
+		nodeDesc := getStr(c.node.Properties, "description")
+		desc := fmt.Sprintf("The %s domain is an architectural grouping in the %s codebase", domainName, c.RepoName)
+		if nodeDesc != "" {
+			desc += ". " + nodeDesc
+		}
+		desc += fmt.Sprintf(" It contains %d source files.", fileCount)
+		faqs = append(faqs, faqEntry{fmt.Sprintf("What is the %s domain?", domainName), desc})
+
+		if len(subs) > 0 {
+			names := c.resolveNames(subs)
+			sort.Strings(names)
+			faqs = append(faqs, faqEntry{
+				fmt.Sprintf("What subdomains are in %s?", domainName),
+				fmt.Sprintf("The %s domain contains %d subdomain(s): %s.", domainName, len(subs), strings.Join(names, ", ")),
+			})
+		}
+
+		faqs = append(faqs, faqEntry{
+			fmt.Sprintf("How many files are in %s?", domainName),
+			fmt.Sprintf("The %s domain contains %d source files.", domainName, fileCount),
+		})
+
+	case "Subdomain":
+		subName := name
+		parentDomain := c.NodeName(c.PartOfDomain[c.node.ID])
+		fileCount := len(c.SubdomainFiles[c.node.ID])
+		funcs := c.SubdomainFuncs[c.node.ID]
+
+		nodeDesc := getStr(c.node.Properties, "description")
+		desc := fmt.Sprintf("%s is a subdomain in the %s codebase", subName, c.RepoName)
+		if parentDomain != "" {
+			desc += fmt.Sprintf(", part of the %s domain", parentDomain)
+		}
+		if nodeDesc != "" {
+			desc += ". " + nodeDesc
+		}
+		desc += fmt.Sprintf(" It contains %d source files.", fileCount)
+		faqs = append(faqs, faqEntry{fmt.Sprintf("What is the %s subdomain?", subName), desc})
+
+		if parentDomain != "" {
+			faqs = append(faqs, faqEntry{
+				fmt.Sprintf("Which domain does %s belong to?", subName),
+				fmt.Sprintf("%s belongs to the %s domain.", subName, parentDomain),
+			})
+		}
+
+		if len(funcs) > 0 {
+			names := c.resolveNames(funcs)
+			sort.Strings(names)
+			listed := names
+			if len(listed) > 8 {
+				listed = listed[:8]
+			}
+			a := fmt.Sprintf("The %s subdomain contains %d function(s): %s", subName, len(funcs), strings.Join(listed, ", "))
+			if len(funcs) > 8 {
+				a += fmt.Sprintf(", and %d more", len(funcs)-8)
+			}
+			a += "."
+			faqs = append(faqs, faqEntry{fmt.Sprintf("What functions are in %s?", subName), a})
+		}
+
+	case "Directory":
+		dirName := getStr(c.node.Properties, "name")
+		if dirName == "" {
+			dirName = filepath.Base(getStr(c.node.Properties, "path"))
+		}
+		files := c.ContainsFile[c.node.ID]
+		subdirs := c.ChildDir[c.node.ID]
+
+		desc := fmt.Sprintf("The %s/ directory contains %d files and %d subdirectories in the %s codebase.", dirName, len(files), len(subdirs), c.RepoName)
+		faqs = append(faqs, faqEntry{fmt.Sprintf("What's in the %s/ directory?", dirName), desc})
+
+		if len(subdirs) > 0 {
+			names := c.resolveNames(subdirs)
+			sort.Strings(names)
+			faqs = append(faqs, faqEntry{
+				fmt.Sprintf("What subdirectories does %s/ contain?", dirName),
+				fmt.Sprintf("%s/ contains %d subdirectory(ies): %s.", dirName, len(subdirs), strings.Join(names, ", ")),
+			})
+		}
+	}
+
+	// Extra FAQs from sidecar enrichments
+	if c.enrichment != nil {
+		for _, f := range c.enrichment.FAQs {
+			if f.Question != "" && f.Answer != "" {
+				faqs = append(faqs, faqEntry{f.Question, f.Answer})
+			}
+		}
+	}
+
+	// Require minimum 2 FAQs
+	if len(faqs) < 2 {
+		return
+	}
+
+	sb.WriteString("## FAQs\n\n")
+	for _, faq := range faqs {
+		sb.WriteString(fmt.Sprintf("### %s\n\n%s\n\n", faq.q, faq.a))
+	}
+}
@@ -0,0 +1,344 @@
+package graph2md
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// --- Frontmatter writers ---
+
+func (c *renderContext) writeFileFrontmatter(sb *strings.Builder) {
+	props := c.node.Properties
+	path := getStr(props, "path")
+	name := getStr(props, "name")
+	lang := getStr(props, "language")
+	if name == "" {
+		name = filepath.Base(path)
+	}
+
+	title := fmt.Sprintf("%s — %s Source File", name, c.RepoName)
+	desc := fmt.Sprintf("Architecture documentation for %s", name)
+	if lang != "" {
+		desc += fmt.Sprintf(", a %s file", lang)
+	}
+	desc += fmt.Sprintf(" in the %s codebase.", c.RepoName)
+
+	depCount := len(c.Imports[c.node.ID])
+	ibCount := len(c.ImportedBy[c.node.ID])
+	if depCount > 0 || ibCount > 0 {
+		desc += fmt.Sprintf(" %d imports, %d dependents.", depCount, ibCount)
+	}
+
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
+	sb.WriteString("node_type: \"File\"\n")
+	sb.WriteString(fmt.Sprintf("file_path: %q\n", path))
+	sb.WriteString(fmt.Sprintf("file_name: %q\n", name))
+	if lang != "" {
+		sb.WriteString(fmt.Sprintf("language: %q\n", lang))
+	}
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+	sb.WriteString(fmt.Sprintf("repo_url: %q\n", c.RepoURL))
+
+	dir := filepath.Dir(path)
+	if dir != "" && dir != "." {
+		sb.WriteString(fmt.Sprintf("directory: %q\n", dir))
+		parts := strings.Split(dir, "/")
+		if len(parts) > 0 {
+			sb.WriteString(fmt.Sprintf("top_directory: %q\n", parts[0]))
+		}
+	}
+
+	ext := filepath.Ext(name)
+	if ext != "" {
+		sb.WriteString(fmt.Sprintf("extension: %q\n", ext))
+	}
+
+	if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+		sb.WriteString(fmt.Sprintf("domain: %q\n", c.NodeName(d)))
+	}
+	if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+		sb.WriteString(fmt.Sprintf("subdomain: %q\n", c.NodeName(s)))
+	}
+
+	sb.WriteString(fmt.Sprintf("import_count: %d\n", depCount))
+	sb.WriteString(fmt.Sprintf("imported_by_count: %d\n", ibCount))
+
+	funcCount := len(c.DefinesFunc[c.node.ID])
+	classCount := len(c.DeclaresClass[c.node.ID])
+	typeCount := len(c.DefinesType[c.node.ID])
+	sb.WriteString(fmt.Sprintf("function_count: %d\n", funcCount))
+	sb.WriteString(fmt.Sprintf("class_count: %d\n", classCount))
+	sb.WriteString(fmt.Sprintf("type_count: %d\n", typeCount))
+
+	c.writeTags(sb)
+}
+
+func (c *renderContext) writeFunctionFrontmatter(sb *strings.Builder) {
+	props := c.node.Properties
+	name := getStr(props, "name")
+	filePath := getStr(props, "filePath")
+	lang := getStr(props, "language")
+	startLine := getNum(props, "startLine")
+	endLine := getNum(props, "endLine")
+
+	title := fmt.Sprintf("%s() — %s Function Reference", name, c.RepoName)
+	desc := fmt.Sprintf("Architecture documentation for the %s() function", name)
+	if filePath != "" {
+		desc += fmt.Sprintf(" in %s", filepath.Base(filePath))
+	}
+	desc += fmt.Sprintf(" from the %s codebase.", c.RepoName)
+
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
+	sb.WriteString("node_type: \"Function\"\n")
+	sb.WriteString(fmt.Sprintf("function_name: %q\n", name))
+	if filePath != "" {
+		sb.WriteString(fmt.Sprintf("file_path: %q\n", filePath))
+		dir := filepath.Dir(filePath)
+		if dir != "" && dir != "." {
+			sb.WriteString(fmt.Sprintf("directory: %q\n", dir))
+		}
+	}
+	if lang != "" {
+		sb.WriteString(fmt.Sprintf("language: %q\n", lang))
+	}
+	if startLine > 0 {
+		sb.WriteString(fmt.Sprintf("start_line: %d\n", startLine))
+	}
+	if endLine > 0 {
+		sb.WriteString(fmt.Sprintf("end_line: %d\n", endLine))
+		sb.WriteString(fmt.Sprintf("line_count: %d\n", endLine-startLine+1))
+	}
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+	sb.WriteString(fmt.Sprintf("call_count: %d\n", len(c.Calls[c.node.ID])))
+	sb.WriteString(fmt.Sprintf("called_by_count: %d\n", len(c.CalledBy[c.node.ID])))
+
+	if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+		sb.WriteString(fmt.Sprintf("domain: %q\n", c.NodeName(d)))
+	}
+	if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+		sb.WriteString(fmt.Sprintf("subdomain: %q\n", c.NodeName(s)))
+	}
+
+	c.writeTags(sb)
+}
+
+func (c *renderContext) writeClassFrontmatter(sb *strings.Builder) {
+	props := c.node.Properties
+	name := getStr(props, "name")
+	filePath := getStr(props, "filePath")
+	lang := getStr(props, "language")
+	startLine := getNum(props, "startLine")
+	endLine := getNum(props, "endLine")
+
+	title := fmt.Sprintf("%s Class — %s Architecture", name, c.RepoName)
+	desc := fmt.Sprintf("Architecture documentation for the %s class", name)
+	if filePath != "" {
+		desc += fmt.Sprintf(" in %s", filepath.Base(filePath))
+	}
+	desc += fmt.Sprintf(" from the %s codebase.", c.RepoName)
+
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
+	sb.WriteString("node_type: \"Class\"\n")
+	sb.WriteString(fmt.Sprintf("class_name: %q\n", name))
+	if filePath != "" {
+		sb.WriteString(fmt.Sprintf("file_path: %q\n", filePath))
+		dir := filepath.Dir(filePath)
+		if dir != "" && dir != "." {
+			sb.WriteString(fmt.Sprintf("directory: %q\n", dir))
+		}
+	}
+	if lang != "" {
+		sb.WriteString(fmt.Sprintf("language: %q\n", lang))
+	}
+	if startLine > 0 {
+		sb.WriteString(fmt.Sprintf("start_line: %d\n", startLine))
+	}
+	if endLine > 0 {
+		sb.WriteString(fmt.Sprintf("end_line: %d\n", endLine))
+		sb.WriteString(fmt.Sprintf("line_count: %d\n", endLine-startLine+1))
+	}
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+
+	if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+		sb.WriteString(fmt.Sprintf("domain: %q\n", c.NodeName(d)))
+	}
+	if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+		sb.WriteString(fmt.Sprintf("subdomain: %q\n", c.NodeName(s)))
+	}
+
+	extends := c.Extends[c.node.ID]
+	if len(extends) > 0 {
+		names := c.resolveNames(extends)
+		sb.WriteString(fmt.Sprintf("extends: %q\n", strings.Join(names, ", ")))
+	}
+
+	c.writeTags(sb)
+}
+
+func (c *renderContext) writeTypeFrontmatter(sb *strings.Builder) {
+	props := c.node.Properties
+	name := getStr(props, "name")
+	filePath := getStr(props, "filePath")
+	lang := getStr(props, "language")
+	startLine := getNum(props, "startLine")
+	endLine := getNum(props, "endLine")
+
+	title := fmt.Sprintf("%s Type — %s Architecture", name, c.RepoName)
+	desc := fmt.Sprintf("Architecture documentation for the %s type/interface", name)
+	if filePath != "" {
+		desc += fmt.Sprintf(" in %s", filepath.Base(filePath))
+	}
+	desc += fmt.Sprintf(" from the %s codebase.", c.RepoName)
+
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
+	sb.WriteString("node_type: \"Type\"\n")
+	sb.WriteString(fmt.Sprintf("type_name: %q\n", name))
+	if filePath != "" {
+		sb.WriteString(fmt.Sprintf("file_path: %q\n", filePath))
+		dir := filepath.Dir(filePath)
+		if dir != "" && dir != "." {
+			sb.WriteString(fmt.Sprintf("directory: %q\n", dir))
+		}
+	}
+	if lang != "" {
+		sb.WriteString(fmt.Sprintf("language: %q\n", lang))
+	}
+	if startLine > 0 {
+		sb.WriteString(fmt.Sprintf("start_line: %d\n", startLine))
+	}
+	if endLine > 0 {
+		sb.WriteString(fmt.Sprintf("end_line: %d\n", endLine))
+		sb.WriteString(fmt.Sprintf("line_count: %d\n", endLine-startLine+1))
+	}
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+
+	if d, ok := c.BelongsToDomain[c.node.ID]; ok {
+		sb.WriteString(fmt.Sprintf("domain: %q\n", c.NodeName(d)))
+	}
+	if s, ok := c.BelongsToSubdomain[c.node.ID]; ok {
+		sb.WriteString(fmt.Sprintf("subdomain: %q\n", c.NodeName(s)))
+	}
+
+	c.writeTags(sb)
+}
+
+func (c *renderContext) writeDomainFrontmatter(sb *strings.Builder) {
+	name := getStr(c.node.Properties, "name")
+	if name == "" {
+		name = c.node.ID
+	}
+
+	nodeDesc := getStr(c.node.Properties, "description")
+	fileCount := len(c.DomainFiles[c.node.ID])
+	title := fmt.Sprintf("%s Domain — %s Architecture", name, c.RepoName)
+	desc := ""
+	if nodeDesc != "" {
+		desc = nodeDesc + " "
+	}
+	desc += fmt.Sprintf("Architectural overview of the %s domain in the %s codebase. Contains %d source files.", name, c.RepoName, fileCount)
+
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
+	sb.WriteString("node_type: \"Domain\"\n")
+	sb.WriteString(fmt.Sprintf("domain: %q\n", name))
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+	sb.WriteString(fmt.Sprintf("file_count: %d\n", fileCount))
+	if nodeDesc != "" {
+		sb.WriteString(fmt.Sprintf("summary: %q\n", nodeDesc))
+	}
+
+	c.writeTags(sb)
+}
+
+func (c *renderContext) writeSubdomainFrontmatter(sb *strings.Builder) {
+	name := getStr(c.node.Properties, "name")
+	if name == "" {
+		name = c.node.ID
+	}
+
+	nodeDesc := getStr(c.node.Properties, "description")
+	parentDomain := c.NodeName(c.PartOfDomain[c.node.ID])
+	fileCount := len(c.SubdomainFiles[c.node.ID])
+
+	title := fmt.Sprintf("%s — %s Architecture", name, c.RepoName)
+	desc := ""
+	if nodeDesc != "" {
+		desc = nodeDesc + " "
+	}
+	desc += fmt.Sprintf("Architecture documentation for the %s subdomain", name)
+	if parentDomain != "" {
+		desc += fmt.Sprintf(" (part of %s domain)", parentDomain)
+	}
+	desc += fmt.Sprintf(" in the %s codebase. Contains %d source files.", c.RepoName, fileCount)
+
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
+	sb.WriteString("node_type: \"Subdomain\"\n")
+	sb.WriteString(fmt.Sprintf("subdomain: %q\n", name))
+	if parentDomain != "" {
+		sb.WriteString(fmt.Sprintf("domain: %q\n", parentDomain))
+	}
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+	sb.WriteString(fmt.Sprintf("file_count: %d\n", fileCount))
+	if nodeDesc != "" {
+		sb.WriteString(fmt.Sprintf("summary: %q\n", nodeDesc))
+	}
+
+	c.writeTags(sb)
+}
+
+func (c *renderContext) writeDirectoryFrontmatter(sb *strings.Builder) {
+	props := c.node.Properties
+	name := getStr(props, "name")
+	path := getStr(props, "path")
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	if path == "" {
+		path = name
+	}
+
+	// Skip root directory
+	if strings.Contains(path, "/app/repo-root/") {
+		return
+	}
+
+	fileCount := len(c.ContainsFile[c.node.ID])
+	subdirCount := len(c.ChildDir[c.node.ID])
+
+	title := fmt.Sprintf("%s/ — %s Directory Structure", path, c.RepoName)
+	desc := fmt.Sprintf("Directory listing for %s/ in the %s codebase. Contains %d files and %d subdirectories.", path, c.RepoName, fileCount, subdirCount)
+
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", desc))
+	sb.WriteString("node_type: \"Directory\"\n")
+	sb.WriteString(fmt.Sprintf("dir_name: %q\n", name))
+	sb.WriteString(fmt.Sprintf("dir_path: %q\n", path))
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+	sb.WriteString(fmt.Sprintf("file_count: %d\n", fileCount))
+	sb.WriteString(fmt.Sprintf("subdir_count: %d\n", subdirCount))
+
+	parts := strings.Split(path, "/")
+	if len(parts) > 0 {
+		sb.WriteString(fmt.Sprintf("top_directory: %q\n", parts[0]))
+	}
+
+	c.writeTags(sb)
+}
+
+// writeProvenance marks the page as generated by graph2md and records which
+// commit and graph snapshot it reflects.
+func (c *renderContext) writeProvenance(sb *strings.Builder) {
+	sb.WriteString(generatorMarker + "\n")
+	if c.Commit != "" {
+		sb.WriteString(fmt.Sprintf("commit: %q\n", c.Commit))
+	}
+	if c.GeneratedAt != "" {
+		sb.WriteString(fmt.Sprintf("generated_at: %q\n", c.GeneratedAt))
+	}
+}
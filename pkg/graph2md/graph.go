@@ -0,0 +1,116 @@
+// Package graph2md converts Supermodel architecture graphs into markdown
+// documentation. It exposes the graph model, an index builder over the
+// graph's relationships, slug assignment, and a Renderer that produces one
+// markdown page per entity.
+package graph2md
+
+import "encoding/json"
+
+// Graph JSON structures matching Supermodel API response
+
+type APIResponse struct {
+	Status string          `json:"status"`
+	JobID  string          `json:"jobId"`
+	Error  json.RawMessage `json:"error"`
+	Result *GraphResult    `json:"result"`
+}
+
+type GraphResult struct {
+	GeneratedAt string          `json:"generatedAt"`
+	Message     string          `json:"message"`
+	Stats       GraphStats      `json:"stats"`
+	Graph       Graph           `json:"graph"`
+	Metadata    json.RawMessage `json:"metadata"`
+	Domains     []DomainSummary `json:"domains"`
+	Artifacts   []Artifact      `json:"artifacts"`
+}
+
+type GraphStats struct {
+	NodeCount         int            `json:"nodeCount"`
+	RelationshipCount int            `json:"relationshipCount"`
+	NodeTypes         map[string]int `json:"nodeTypes"`
+	RelationshipTypes map[string]int `json:"relationshipTypes"`
+}
+
+type Graph struct {
+	Nodes         []Node         `json:"nodes"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+type Node struct {
+	ID         string                 `json:"id"`
+	Labels     []string               `json:"labels"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type Relationship struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	StartNode  string                 `json:"startNode"`
+	EndNode    string                 `json:"endNode"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type DomainSummary struct {
+	Name       string             `json:"name"`
+	Subdomains []SubdomainSummary `json:"subdomains"`
+	Files      []string           `json:"files"`
+}
+
+type SubdomainSummary struct {
+	Name               string   `json:"name"`
+	DescriptionSummary string   `json:"descriptionSummary"`
+	Files              []string `json:"files"`
+	Functions          []string `json:"functions"`
+	Classes            []string `json:"classes"`
+}
+
+type Artifact struct {
+	Type  string          `json:"type"`
+	Name  string          `json:"name"`
+	Graph *Graph          `json:"graph"`
+	Stats json.RawMessage `json:"stats"`
+}
+
+// PrimaryLabel returns the node's first label, which determines its page type.
+func (n *Node) PrimaryLabel() string {
+	if len(n.Labels) == 0 {
+		return ""
+	}
+	return n.Labels[0]
+}
+
+func hasLabel(node *Node, label string) bool {
+	for _, l := range node.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func getStr(m map[string]interface{}, key string) string {
+	v, ok := m[key]
+	if !ok {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		return ""
+	}
+	return s
+}
+
+func getNum(m map[string]interface{}, key string) int {
+	v, ok := m[key]
+	if !ok {
+		return 0
+	}
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
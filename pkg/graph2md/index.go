@@ -0,0 +1,261 @@
+package graph2md
+
+// Index holds the relationship lookups used to render entity pages. It is
+// read-only once built and safe to share between renderers.
+type Index struct {
+	Nodes              map[string]*Node    // node ID -> node
+	Imports            map[string][]string // file -> imported files
+	ImportedBy         map[string][]string // file -> importing files
+	Calls              map[string][]string // function -> callees
+	CalledBy           map[string][]string // function -> callers
+	ContainsFile       map[string][]string // directory -> files
+	DefinesFunc        map[string][]string // file or class -> functions
+	DeclaresClass      map[string][]string // file -> classes
+	DefinesType        map[string][]string // file -> types
+	ChildDir           map[string][]string // directory -> subdirectories
+	Extends            map[string][]string // class -> parent classes
+	BelongsToDomain    map[string]string   // node -> domain node ID
+	BelongsToSubdomain map[string]string   // node -> subdomain node ID
+	PartOfDomain       map[string]string   // subdomain node ID -> domain node ID
+	DomainFiles        map[string][]string // domain node ID -> file node IDs
+	SubdomainFiles     map[string][]string // subdomain node ID -> file node IDs
+
+	// Reverse lookups for "Defined In"
+	FileOfFunc  map[string]string // function node ID -> file node ID
+	FileOfClass map[string]string // class node ID -> file node ID
+	FileOfType  map[string]string // type node ID -> file node ID
+
+	DomainSubdomains map[string][]string // domain node ID -> subdomain node IDs
+	SubdomainFuncs   map[string][]string // subdomain node ID -> function node IDs
+	SubdomainClasses map[string][]string // subdomain node ID -> class node IDs
+}
+
+// NodeName returns the name of the node with the given ID, or "" if there
+// is no such node.
+func (idx *Index) NodeName(id string) string {
+	if n := idx.Nodes[id]; n != nil {
+		return getStr(n.Properties, "name")
+	}
+	return ""
+}
+
+// BuildIndex builds the relationship indices for a merged set of nodes and
+// relationships. Domain and subdomain membership is propagated to files from
+// the functions and classes they contain. Domains and subdomains are keyed by
+// node ID, not name, since names repeat across the repos of a merged graph.
+func BuildIndex(nodes []Node, rels []Relationship) *Index {
+	// Build node lookup: id -> node
+	nodeLookup := make(map[string]*Node)
+	for i := range nodes {
+		nodeLookup[nodes[i].ID] = &nodes[i]
+	}
+
+	// Build relationship indices
+	imports := make(map[string][]string)
+	importedBy := make(map[string][]string)
+	callsRel := make(map[string][]string)
+	calledByRel := make(map[string][]string)
+	containsFile := make(map[string][]string)     // directory -> files
+	definesFunc := make(map[string][]string)      // file -> functions
+	declaresClass := make(map[string][]string)    // file -> classes
+	definesType := make(map[string][]string)      // file -> types
+	childDir := make(map[string][]string)         // directory -> subdirectories
+	belongsToDomain := make(map[string]string)    // node -> domain node ID
+	belongsToSubdomain := make(map[string]string) // node -> subdomain node ID
+	partOfDomain := make(map[string]string)       // subdomain node ID -> domain node ID
+	extendsRel := make(map[string][]string)       // class -> parent classes
+
+	// Reverse lookups for "Defined In"
+	fileOfFunc := make(map[string]string)  // function nodeID -> file nodeID
+	fileOfClass := make(map[string]string) // class nodeID -> file nodeID
+	fileOfType := make(map[string]string)  // type nodeID -> file nodeID
+
+	// Domain -> subdomain mappings
+	domainSubdomains := make(map[string][]string) // domain node ID -> subdomain node IDs
+
+	// Subdomain -> functions/classes
+	subdomainFuncs := make(map[string][]string)   // subdomain node ID -> function node IDs
+	subdomainClasses := make(map[string][]string) // subdomain node ID -> class node IDs
+
+	for _, rel := range rels {
+		switch rel.Type {
+		case "IMPORTS":
+			imports[rel.StartNode] = append(imports[rel.StartNode], rel.EndNode)
+			importedBy[rel.EndNode] = append(importedBy[rel.EndNode], rel.StartNode)
+		case "calls":
+			callsRel[rel.StartNode] = append(callsRel[rel.StartNode], rel.EndNode)
+			calledByRel[rel.EndNode] = append(calledByRel[rel.EndNode], rel.StartNode)
+		case "CONTAINS_FILE":
+			containsFile[rel.StartNode] = append(containsFile[rel.StartNode], rel.EndNode)
+		case "DEFINES_FUNCTION":
+			definesFunc[rel.StartNode] = append(definesFunc[rel.StartNode], rel.EndNode)
+			fileOfFunc[rel.EndNode] = rel.StartNode
+		case "DECLARES_CLASS":
+			declaresClass[rel.StartNode] = append(declaresClass[rel.StartNode], rel.EndNode)
+			fileOfClass[rel.EndNode] = rel.StartNode
+		case "DEFINES":
+			definesType[rel.StartNode] = append(definesType[rel.StartNode], rel.EndNode)
+			fileOfType[rel.EndNode] = rel.StartNode
+		case "CHILD_DIRECTORY":
+			childDir[rel.StartNode] = append(childDir[rel.StartNode], rel.EndNode)
+		case "EXTENDS":
+			extendsRel[rel.StartNode] = append(extendsRel[rel.StartNode], rel.EndNode)
+		case "belongsTo":
+			endNode := nodeLookup[rel.EndNode]
+			if endNode == nil {
+				continue
+			}
+			if hasLabel(endNode, "Domain") {
+				belongsToDomain[rel.StartNode] = endNode.ID
+			} else if hasLabel(endNode, "Subdomain") {
+				belongsToSubdomain[rel.StartNode] = endNode.ID
+			}
+		case "partOf":
+			if nodeLookup[rel.EndNode] != nil {
+				partOfDomain[rel.StartNode] = rel.EndNode
+			}
+		}
+	}
+
+	// Build domain -> subdomain mapping from partOf relationships
+	for subNodeID, domID := range partOfDomain {
+		domainSubdomains[domID] = append(domainSubdomains[domID], subNodeID)
+	}
+
+	// Build subdomain -> functions/classes from belongsToSubdomain
+	for nodeID, subID := range belongsToSubdomain {
+		n := nodeLookup[nodeID]
+		if n == nil {
+			continue
+		}
+		if hasLabel(n, "Function") {
+			subdomainFuncs[subID] = append(subdomainFuncs[subID], nodeID)
+		} else if hasLabel(n, "Class") {
+			subdomainClasses[subID] = append(subdomainClasses[subID], nodeID)
+		}
+	}
+
+	// Resolve domain for files via belongsTo on their functions/classes
+	// (files might not have direct belongsTo, but their contents do)
+	// Also check functions belonging to classes declared in the file.
+	for _, node := range nodes {
+		if !hasLabel(&node, "File") {
+			continue
+		}
+		if _, ok := belongsToDomain[node.ID]; ok {
+			continue
+		}
+		// Check functions in this file
+		for _, fnID := range definesFunc[node.ID] {
+			if d, ok := belongsToDomain[fnID]; ok {
+				belongsToDomain[node.ID] = d
+				break
+			}
+		}
+		if _, ok := belongsToDomain[node.ID]; ok {
+			continue
+		}
+		// Check classes and their methods
+		for _, clsID := range declaresClass[node.ID] {
+			if d, ok := belongsToDomain[clsID]; ok {
+				belongsToDomain[node.ID] = d
+				break
+			}
+			// Check functions defined on this class
+			for _, fnID := range definesFunc[clsID] {
+				if d, ok := belongsToDomain[fnID]; ok {
+					belongsToDomain[node.ID] = d
+					break
+				}
+			}
+			if _, ok := belongsToDomain[node.ID]; ok {
+				break
+			}
+		}
+	}
+
+	// Similarly resolve subdomain for files
+	for _, node := range nodes {
+		if !hasLabel(&node, "File") {
+			continue
+		}
+		if _, ok := belongsToSubdomain[node.ID]; ok {
+			continue
+		}
+		for _, fnID := range definesFunc[node.ID] {
+			if s, ok := belongsToSubdomain[fnID]; ok {
+				belongsToSubdomain[node.ID] = s
+				break
+			}
+		}
+		if _, ok := belongsToSubdomain[node.ID]; ok {
+			continue
+		}
+		for _, clsID := range declaresClass[node.ID] {
+			if s, ok := belongsToSubdomain[clsID]; ok {
+				belongsToSubdomain[node.ID] = s
+				break
+			}
+			for _, fnID := range definesFunc[clsID] {
+				if s, ok := belongsToSubdomain[fnID]; ok {
+					belongsToSubdomain[node.ID] = s
+					break
+				}
+			}
+			if _, ok := belongsToSubdomain[node.ID]; ok {
+				break
+			}
+		}
+	}
+
+	// Propagate domain from subdomain's partOf for any node that has a
+	// subdomain but no direct domain assignment.
+	for nodeID, subID := range belongsToSubdomain {
+		if _, ok := belongsToDomain[nodeID]; ok {
+			continue
+		}
+		if domID, ok := partOfDomain[subID]; ok {
+			belongsToDomain[nodeID] = domID
+		}
+	}
+
+	// Collect all domain members for Domain/Subdomain body sections
+	domainFiles := make(map[string][]string)    // domain node ID -> file node IDs
+	subdomainFiles := make(map[string][]string) // subdomain node ID -> file node IDs
+	for nodeID, domID := range belongsToDomain {
+		n := nodeLookup[nodeID]
+		if n != nil && hasLabel(n, "File") {
+			domainFiles[domID] = append(domainFiles[domID], nodeID)
+		}
+	}
+	for nodeID, subID := range belongsToSubdomain {
+		n := nodeLookup[nodeID]
+		if n != nil && hasLabel(n, "File") {
+			subdomainFiles[subID] = append(subdomainFiles[subID], nodeID)
+		}
+	}
+	return &Index{
+		Nodes:              nodeLookup,
+		Imports:            imports,
+		ImportedBy:         importedBy,
+		Calls:              callsRel,
+		CalledBy:           calledByRel,
+		ContainsFile:       containsFile,
+		DefinesFunc:        definesFunc,
+		DeclaresClass:      declaresClass,
+		DefinesType:        definesType,
+		ChildDir:           childDir,
+		Extends:            extendsRel,
+		BelongsToDomain:    belongsToDomain,
+		BelongsToSubdomain: belongsToSubdomain,
+		PartOfDomain:       partOfDomain,
+		DomainFiles:        domainFiles,
+		SubdomainFiles:     subdomainFiles,
+		FileOfFunc:         fileOfFunc,
+		FileOfClass:        fileOfClass,
+		FileOfType:         fileOfType,
+		DomainSubdomains:   domainSubdomains,
+		SubdomainFuncs:     subdomainFuncs,
+		SubdomainClasses:   subdomainClasses,
+	}
+}
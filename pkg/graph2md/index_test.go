@@ -0,0 +1,83 @@
+package graph2md
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// twoRepoGraph returns a merged graph of two repos that both have an Auth
+// domain with a Sessions subdomain.
+func twoRepoGraph() (Graph, map[string]string) {
+	g := Graph{}
+	nodeRepos := make(map[string]string)
+	for _, repo := range []string{"a", "b"} {
+		p := repo + ":"
+		g.Nodes = append(g.Nodes,
+			testNode(p+"dom", "Domain", "name", "Auth"),
+			testNode(p+"sub", "Subdomain", "name", "Sessions"),
+			testNode(p+"file", "File", "path", repo+"/auth.ts", "name", "auth.ts"),
+			testNode(p+"fn", "Function", "name", "login", "filePath", repo+"/auth.ts"),
+		)
+		g.Relationships = append(g.Relationships,
+			testRel(p+"r1", "partOf", p+"sub", p+"dom"),
+			testRel(p+"r2", "DEFINES_FUNCTION", p+"file", p+"fn"),
+			testRel(p+"r3", "belongsTo", p+"fn", p+"sub"),
+		)
+		for _, n := range g.Nodes {
+			if _, ok := nodeRepos[n.ID]; !ok {
+				nodeRepos[n.ID] = repo
+			}
+		}
+	}
+	return g, nodeRepos
+}
+
+func TestBuildIndexSameNamedDomains(t *testing.T) {
+	g, _ := twoRepoGraph()
+	idx := BuildIndex(g.Nodes, g.Relationships)
+
+	for _, p := range []string{"a:", "b:"} {
+		// The file gets its subdomain from its function, and its domain
+		// from the subdomain, all within its own repo.
+		if got := idx.BelongsToSubdomain[p+"file"]; got != p+"sub" {
+			t.Errorf("BelongsToSubdomain[%sfile] = %q, want %ssub", p, got, p)
+		}
+		if got := idx.BelongsToDomain[p+"file"]; got != p+"dom" {
+			t.Errorf("BelongsToDomain[%sfile] = %q, want %sdom", p, got, p)
+		}
+		if got, want := idx.DomainFiles[p+"dom"], []string{p + "file"}; !slices.Equal(got, want) {
+			t.Errorf("DomainFiles[%sdom] = %v, want %v", p, got, want)
+		}
+		if got, want := idx.SubdomainFiles[p+"sub"], []string{p + "file"}; !slices.Equal(got, want) {
+			t.Errorf("SubdomainFiles[%ssub] = %v, want %v", p, got, want)
+		}
+		if got, want := idx.DomainSubdomains[p+"dom"], []string{p + "sub"}; !slices.Equal(got, want) {
+			t.Errorf("DomainSubdomains[%sdom] = %v, want %v", p, got, want)
+		}
+		if got, want := idx.SubdomainFuncs[p+"sub"], []string{p + "fn"}; !slices.Equal(got, want) {
+			t.Errorf("SubdomainFuncs[%ssub] = %v, want %v", p, got, want)
+		}
+	}
+	if got := idx.NodeName("b:dom"); got != "Auth" {
+		t.Errorf("NodeName(b:dom) = %q, want Auth", got)
+	}
+}
+
+func TestRenderSameNamedDomains(t *testing.T) {
+	g, nodeRepos := twoRepoGraph()
+	idx := BuildIndex(g.Nodes, g.Relationships)
+	entries, slugs := AssignRepoSlugs(g.Nodes, nodeRepos)
+	r := &Renderer{Index: idx, Slugs: slugs, NodeRepos: nodeRepos}
+
+	pages := make(map[string]string)
+	for _, e := range entries {
+		pages[e.Node.ID] = r.RenderEntry(e)
+	}
+	if page := pages["b:dom"]; !strings.Contains(page, "file_count: 1\n") || !strings.Contains(page, slugs["b:file"]) || strings.Contains(page, slugs["a:file"]) {
+		t.Errorf("b's Auth domain page should list only b's file:\n%s", page)
+	}
+	if page := pages["b:fn"]; !strings.Contains(page, slugs["b:sub"]) || strings.Contains(page, slugs["a:sub"]) {
+		t.Errorf("b's function page should link b's Sessions subdomain:\n%s", page)
+	}
+}
@@ -0,0 +1,286 @@
+package graph2md
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Page is a generated page that doesn't correspond to a single entity.
+type Page struct {
+	Slug    string
+	Title   string // short title, e.g. "Functions"
+	Content string
+}
+
+// ComputeStats counts the nodes and relationships of a graph by type, in
+// the same shape as the stats the Supermodel API reports.
+func ComputeStats(nodes []Node, rels []Relationship) GraphStats {
+	stats := GraphStats{
+		NodeCount:         len(nodes),
+		RelationshipCount: len(rels),
+		NodeTypes:         make(map[string]int),
+		RelationshipTypes: make(map[string]int),
+	}
+	for i := range nodes {
+		if l := nodes[i].PrimaryLabel(); l != "" {
+			stats.NodeTypes[l]++
+		}
+	}
+	for _, rel := range rels {
+		stats.RelationshipTypes[rel.Type]++
+	}
+	return stats
+}
+
+// indexPage describes one per-type index page.
+type indexPage struct {
+	slug, label, title string
+	columns            []string
+	row                func(c *renderContext, e Entry) []string
+}
+
+var indexPages = []indexPage{
+	{
+		slug: "domains", label: "Domain", title: "Domains",
+		columns: []string{"Domain", "Subdomains", "Files", "Description"},
+		row: func(c *renderContext, e Entry) []string {
+			return []string{
+				c.internalLink(e.Node.ID, getStr(e.Node.Properties, "name")),
+				fmt.Sprint(len(c.DomainSubdomains[e.Node.ID])),
+				fmt.Sprint(len(c.DomainFiles[e.Node.ID])),
+				c.text(getStr(e.Node.Properties, "description")),
+			}
+		},
+	},
+	{
+		slug: "files", label: "File", title: "Files",
+		columns: []string{"File", "Language", "Domain", "Imports", "Imported By"},
+		row: func(c *renderContext, e Entry) []string {
+			return []string{
+				c.internalLink(e.Node.ID, getStr(e.Node.Properties, "path")),
+				c.text(getStr(e.Node.Properties, "language")),
+				c.domainCell(e.Node.ID),
+				fmt.Sprint(len(c.Imports[e.Node.ID])),
+				fmt.Sprint(len(c.ImportedBy[e.Node.ID])),
+			}
+		},
+	},
+	{
+		slug: "functions", label: "Function", title: "Functions",
+		columns: []string{"Function", "File", "Domain", "Calls", "Called By"},
+		row: func(c *renderContext, e Entry) []string {
+			return []string{
+				c.internalLink(e.Node.ID, getStr(e.Node.Properties, "name")+"()"),
+				c.definedInCell(c.FileOfFunc, e.Node),
+				c.domainCell(e.Node.ID),
+				fmt.Sprint(len(c.Calls[e.Node.ID])),
+				fmt.Sprint(len(c.CalledBy[e.Node.ID])),
+			}
+		},
+	},
+	{
+		slug: "classes", label: "Class", title: "Classes",
+		columns: []string{"Class", "File", "Domain", "Methods"},
+		row: func(c *renderContext, e Entry) []string {
+			return []string{
+				c.internalLink(e.Node.ID, getStr(e.Node.Properties, "name")),
+				c.definedInCell(c.FileOfClass, e.Node),
+				c.domainCell(e.Node.ID),
+				fmt.Sprint(len(c.DefinesFunc[e.Node.ID])),
+			}
+		},
+	},
+	{
+		slug: "types", label: "Type", title: "Types",
+		columns: []string{"Type", "File", "Domain"},
+		row: func(c *renderContext, e Entry) []string {
+			return []string{
+				c.internalLink(e.Node.ID, getStr(e.Node.Properties, "name")),
+				c.definedInCell(c.FileOfType, e.Node),
+				c.domainCell(e.Node.ID),
+			}
+		},
+	},
+	{
+		slug: "directories", label: "Directory", title: "Directories",
+		columns: []string{"Directory", "Files", "Subdirectories"},
+		row: func(c *renderContext, e Entry) []string {
+			return []string{
+				c.internalLink(e.Node.ID, getStr(e.Node.Properties, "path")+"/"),
+				fmt.Sprint(len(c.ContainsFile[e.Node.ID])),
+				fmt.Sprint(len(c.ChildDir[e.Node.ID])),
+			}
+		},
+	},
+}
+
+// RenderIndexPages returns the repository overview page and one index page
+// per entity type, listing every entry of that type in a table sorted by
+// path and name. Index pages for types with no entries are omitted.
+func (r *Renderer) RenderIndexPages(entries []Entry, stats GraphStats) []Page {
+	c := r.siteContext()
+
+	byLabel := make(map[string][]Entry)
+	for _, e := range entries {
+		byLabel[e.Label] = append(byLabel[e.Label], e)
+	}
+
+	var pages []Page
+	for _, ip := range indexPages {
+		list := byLabel[ip.label]
+		if len(list) == 0 {
+			continue
+		}
+		sort.Slice(list, func(i, j int) bool {
+			a, b := c.indexSortKey(list[i]), c.indexSortKey(list[j])
+			if a != b {
+				return a < b
+			}
+			return list[i].Slug < list[j].Slug
+		})
+		pages = append(pages, c.renderIndexPage(ip, list))
+	}
+
+	return append([]Page{c.renderOverview(pages, byLabel["Domain"], stats)}, pages...)
+}
+
+// indexSortKey orders entries by file path, then by name within a file.
+func (c *renderContext) indexSortKey(e Entry) string {
+	return c.resolveNameWithPath(e.Node.ID) + "\x00" + getStr(e.Node.Properties, "name")
+}
+
+func (c *renderContext) renderIndexPage(ip indexPage, list []Entry) Page {
+	// Links are relative to the index page, which may be nested.
+	pc := *c
+	pc.slug = ip.slug
+	c = &pc
+
+	var sb strings.Builder
+	title := fmt.Sprintf("%s — %s Architecture", ip.title, c.RepoName)
+
+	sb.WriteString("---\n")
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", fmt.Sprintf("Index of all %d %s in the %s codebase.", len(list), strings.ToLower(ip.title), c.RepoName)))
+	sb.WriteString("node_type: \"Index\"\n")
+	sb.WriteString(fmt.Sprintf("index_type: %q\n", ip.label))
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+	sb.WriteString(fmt.Sprintf("entity_count: %d\n", len(list)))
+	c.writeProvenance(&sb)
+	sb.WriteString("---\n\n")
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", ip.title))
+	writeTableRow(&sb, ip.columns)
+	sep := make([]string, len(ip.columns))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	writeTableRow(&sb, sep)
+	for _, e := range list {
+		writeTableRow(&sb, ip.row(c, e))
+	}
+	sb.WriteString("\n")
+
+	return Page{Slug: ip.slug, Title: ip.title, Content: sb.String()}
+}
+
+func (c *renderContext) renderOverview(indexes []Page, domains []Entry, stats GraphStats) Page {
+	var sb strings.Builder
+	title := fmt.Sprintf("%s — Architecture Overview", c.RepoName)
+
+	sb.WriteString("---\n")
+	sb.WriteString(fmt.Sprintf("title: %q\n", title))
+	sb.WriteString(fmt.Sprintf("description: %q\n", fmt.Sprintf("Architecture overview of the %s codebase: %d nodes and %d relationships.", c.RepoName, stats.NodeCount, stats.RelationshipCount)))
+	sb.WriteString("node_type: \"Overview\"\n")
+	sb.WriteString(fmt.Sprintf("repo: %q\n", c.RepoName))
+	if c.RepoURL != "" {
+		sb.WriteString(fmt.Sprintf("repo_url: %q\n", c.RepoURL))
+	}
+	sb.WriteString(fmt.Sprintf("node_count: %d\n", stats.NodeCount))
+	sb.WriteString(fmt.Sprintf("relationship_count: %d\n", stats.RelationshipCount))
+	c.writeProvenance(&sb)
+	sb.WriteString("---\n\n")
+
+	if len(indexes) > 0 {
+		sb.WriteString("## Browse\n\n")
+		for _, p := range indexes {
+			sb.WriteString(fmt.Sprintf("- %s\n", c.anchor(c.PageURL(p.Slug), p.Title)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(domains) > 0 {
+		sb.WriteString("## Domains\n\n")
+		sort.Slice(domains, func(i, j int) bool {
+			return getStr(domains[i].Node.Properties, "name") < getStr(domains[j].Node.Properties, "name")
+		})
+		for _, d := range domains {
+			line := c.internalLink(d.Node.ID, getStr(d.Node.Properties, "name"))
+			if desc := getStr(d.Node.Properties, "description"); desc != "" {
+				line += " — " + c.text(desc)
+			}
+			sb.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+		sb.WriteString("\n")
+	}
+
+	c.writeCountTable(&sb, "Node Types", "Type", stats.NodeTypes)
+	c.writeCountTable(&sb, "Relationship Types", "Relationship", stats.RelationshipTypes)
+
+	return Page{Slug: "overview", Title: "Overview", Content: sb.String()}
+}
+
+// writeCountTable writes a two-column table of counts, largest first.
+func (c *renderContext) writeCountTable(sb *strings.Builder, heading, column string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	sb.WriteString(fmt.Sprintf("## %s\n\n", heading))
+	writeTableRow(sb, []string{column, "Count"})
+	writeTableRow(sb, []string{"---", "---:"})
+	for _, k := range keys {
+		writeTableRow(sb, []string{c.text(k), fmt.Sprint(counts[k])})
+	}
+	sb.WriteString("\n")
+}
+
+func (c *renderContext) domainCell(nodeID string) string {
+	if d, ok := c.BelongsToDomain[nodeID]; ok {
+		return c.domainLink(d)
+	}
+	return ""
+}
+
+func (c *renderContext) definedInCell(fileOf map[string]string, n Node) string {
+	if fileID, ok := fileOf[n.ID]; ok {
+		return c.internalLink(fileID, c.resolveNameWithPath(fileID))
+	}
+	if fp := getStr(n.Properties, "filePath"); fp != "" {
+		return c.text(filepath.Base(fp))
+	}
+	return ""
+}
+
+// writeTableRow writes one Markdown table row. Pipes and newlines inside
+// cells are escaped or flattened so they don't split the row.
+func writeTableRow(sb *strings.Builder, cells []string) {
+	sb.WriteString("|")
+	for _, cell := range cells {
+		cell = strings.Join(strings.Fields(cell), " ")
+		cell = strings.ReplaceAll(cell, `\|`, "|")
+		cell = strings.ReplaceAll(cell, "|", `\|`)
+		sb.WriteString(" " + cell + " |")
+	}
+	sb.WriteString("\n")
+}
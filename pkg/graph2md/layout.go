@@ -0,0 +1,190 @@
+package graph2md
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Layout selects how entity pages are arranged in the output directory.
+type Layout string
+
+const (
+	// LayoutFlat writes every page as <slug>.md at the top level.
+	LayoutFlat Layout = "flat"
+	// LayoutType nests pages by node type: functions/<slug>.md,
+	// classes/<slug>.md, ...
+	LayoutType Layout = "type"
+	// LayoutDomain nests pages by domain and subdomain:
+	// <domain>/<subdomain>/<slug>.md. Pages outside any domain go under
+	// unassigned/.
+	LayoutDomain Layout = "domain"
+	// LayoutSource mirrors the source tree: a file's page is
+	// src/auth/service.ts.md and its members sit beside it, as in
+	// src/auth/service.ts/AuthService.md.
+	LayoutSource Layout = "source"
+)
+
+// Layouts lists the supported layouts.
+var Layouts = []Layout{LayoutFlat, LayoutType, LayoutDomain, LayoutSource}
+
+// ParseLayout parses a layout name.
+func ParseLayout(s string) (Layout, error) {
+	for _, l := range Layouts {
+		if string(l) == s {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unknown layout %q (want flat, type, domain or source)", s)
+}
+
+// typeDirs names the directory each node type is nested under.
+var typeDirs = map[string]string{
+	"File":      "files",
+	"Function":  "functions",
+	"Class":     "classes",
+	"Type":      "types",
+	"Domain":    "domains",
+	"Subdomain": "subdomains",
+	"Directory": "directories",
+}
+
+// sectionPage is the page name of a directory's own page, as in Hugo's
+// _index.md. With pretty links it is served at the directory's URL.
+const sectionPage = "_index"
+
+// LayoutPaths returns the page path (the output path without ".md") of each
+// entry under layout, keyed by slug, for Renderer.Paths. It returns nil for
+// the flat layout, where every page path is its slug. Entries with a
+// repository are nested under a directory named after it. Pages that would
+// share a path fall back to their slug within the same directory.
+//
+// Under the type layout, an index page (see RenderIndexPages) whose type has
+// a top-level directory becomes that directory's section page, e.g.
+// functions/_index, so it doesn't clash with the directory's URL.
+func LayoutPaths(layout Layout, entries []Entry, idx *Index) map[string]string {
+	if layout == LayoutFlat || layout == "" {
+		return nil
+	}
+	paths := make(map[string]string, len(entries))
+	bySlug := make(map[string][]string)
+	for _, e := range entries {
+		var p string
+		switch layout {
+		case LayoutType:
+			p = typeDirs[e.Label] + "/" + e.Slug
+		case LayoutDomain:
+			p = domainPagePath(e, idx)
+		case LayoutSource:
+			p = sourcePagePath(e)
+		}
+		if e.Repo != "" {
+			p = toSlug(e.Repo) + "/" + p
+		}
+		paths[e.Slug] = p
+		bySlug[p] = append(bySlug[p], e.Slug)
+	}
+
+	for p, slugs := range bySlug {
+		if len(slugs) < 2 {
+			continue
+		}
+		sort.Strings(slugs)
+		for _, slug := range slugs {
+			paths[slug] = path.Join(path.Dir(p), slug)
+		}
+	}
+
+	if layout == LayoutType {
+		for _, ip := range indexPages {
+			if dir := typeDirs[ip.label]; hasDir(bySlug, dir) {
+				paths[ip.slug] = dir + "/" + sectionPage
+			}
+		}
+	}
+	return paths
+}
+
+// hasDir reports whether any of the page paths is under the top-level
+// directory dir.
+func hasDir(pagePaths map[string][]string, dir string) bool {
+	for p := range pagePaths {
+		if strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func domainPagePath(e Entry, idx *Index) string {
+	var domain, sub string
+	switch e.Label {
+	case "Domain":
+		domain = getStr(e.Node.Properties, "name")
+	case "Subdomain":
+		domain = idx.NodeName(idx.PartOfDomain[e.Node.ID])
+		sub = getStr(e.Node.Properties, "name")
+	default:
+		domain = idx.NodeName(idx.BelongsToDomain[e.Node.ID])
+		sub = idx.NodeName(idx.BelongsToSubdomain[e.Node.ID])
+	}
+	dir := toSlug(domain)
+	if dir == "" {
+		dir = "unassigned"
+	}
+	if s := toSlug(sub); s != "" {
+		dir += "/" + s
+	}
+	return dir + "/" + e.Slug
+}
+
+func sourcePagePath(e Entry) string {
+	props := e.Node.Properties
+	var p string
+	switch e.Label {
+	case "File", "Directory":
+		p = getStr(props, "path")
+	case "Function", "Class", "Type":
+		if fp := getStr(props, "filePath"); fp != "" {
+			if name := getStr(props, "name"); name != "" {
+				p = fp + "/" + name
+			}
+		}
+	}
+	if p = sanitizePath(p); p == "" {
+		return typeDirs[e.Label] + "/" + e.Slug
+	}
+	return p
+}
+
+// sanitizePath makes a source path safe to use under the output directory:
+// it is made relative, and each segment keeps only letters, digits, '.',
+// '-' and '_', with "." and ".." segments dropped.
+func sanitizePath(p string) string {
+	var segs []string
+	for _, seg := range strings.Split(strings.ReplaceAll(p, `\`, "/"), "/") {
+		seg = strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+				return r
+			}
+			return '-'
+		}, seg)
+		if seg == "" || seg == "." || seg == ".." {
+			continue
+		}
+		segs = append(segs, seg)
+	}
+	return strings.Join(segs, "/")
+}
+
+// PagePath returns the path of the page with slug relative to the output
+// directory, without the ".md" extension. Pages not in Paths, such as index
+// pages, sit at the top level.
+func (r *Renderer) PagePath(slug string) string {
+	if p, ok := r.Paths[slug]; ok {
+		return p
+	}
+	return slug
+}
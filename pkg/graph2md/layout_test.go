@@ -0,0 +1,162 @@
+package graph2md
+
+import (
+	"strings"
+	"testing"
+)
+
+func layoutGraph() Graph {
+	return Graph{
+		Nodes: []Node{
+			testNode("dom", "Domain", "name", "Auth"),
+			testNode("sub", "Subdomain", "name", "Sessions"),
+			testNode("dir", "Directory", "path", "src", "name", "src"),
+			testNode("file", "File", "path", "src/auth.ts", "name", "auth.ts"),
+			testNode("fn1", "Function", "name", "login", "filePath", "src/auth.ts"),
+			testNode("fn2", "Function", "name", "login", "filePath", "src/auth.ts"), // an overload
+			testNode("fn3", "Function", "name", "helper"),
+			testNode("cls", "Class", "name", "Auth", "filePath", "src/auth.ts"),
+		},
+		Relationships: []Relationship{
+			testRel("r1", "partOf", "sub", "dom"),
+			testRel("r2", "DEFINES_FUNCTION", "file", "fn1"),
+			testRel("r3", "belongsTo", "fn1", "sub"),
+		},
+	}
+}
+
+func TestLayoutPaths(t *testing.T) {
+	g := layoutGraph()
+	idx := BuildIndex(g.Nodes, g.Relationships)
+	entries, slugs := AssignSlugs(g.Nodes)
+
+	tests := []struct {
+		layout Layout
+		want   map[string]string // node ID or index page slug -> page path
+	}{
+		{LayoutType, map[string]string{
+			"dom":  "domains/domain-auth",
+			"sub":  "subdomains/subdomain-sessions",
+			"dir":  "directories/dir-src",
+			"file": "files/file-src-auth-ts",
+			"fn1":  "functions/" + slugs["fn1"],
+			"fn3":  "functions/fn-helper",
+			"cls":  "classes/class-auth-ts-auth",
+			// Index pages become their directory's section page.
+			"functions":   "functions/_index",
+			"classes":     "classes/_index",
+			"domains":     "domains/_index",
+			"files":       "files/_index",
+			"directories": "directories/_index",
+		}},
+		{LayoutDomain, map[string]string{
+			"dom":  "auth/domain-auth",
+			"sub":  "auth/sessions/subdomain-sessions",
+			"dir":  "unassigned/dir-src",
+			"file": "auth/sessions/file-src-auth-ts", // from its function
+			"fn1":  "auth/sessions/" + slugs["fn1"],
+			"fn2":  "unassigned/" + slugs["fn2"],
+			"fn3":  "unassigned/fn-helper",
+		}},
+		{LayoutSource, map[string]string{
+			"dom":  "domains/domain-auth",
+			"dir":  "src",
+			"file": "src/auth.ts",
+			"cls":  "src/auth.ts/Auth",
+			// The overloads would share src/auth.ts/login.
+			"fn1": "src/auth.ts/" + slugs["fn1"],
+			"fn2": "src/auth.ts/" + slugs["fn2"],
+			"fn3": "functions/fn-helper",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.layout), func(t *testing.T) {
+			paths := LayoutPaths(tt.layout, entries, idx)
+			for key, want := range tt.want {
+				slug, ok := slugs[key]
+				if !ok {
+					slug = key
+				}
+				if got := paths[slug]; got != want {
+					t.Errorf("path of %s = %q, want %q", key, got, want)
+				}
+			}
+			if tt.layout != LayoutType {
+				if p, ok := paths["functions"]; ok {
+					t.Errorf("index page moved to %q under the %s layout", p, tt.layout)
+				}
+			}
+		})
+	}
+	if slugs["fn1"] == slugs["fn2"] {
+		t.Fatalf("overloads share slug %q", slugs["fn1"])
+	}
+	if paths := LayoutPaths(LayoutFlat, entries, idx); paths != nil {
+		t.Errorf("flat layout paths = %v, want nil", paths)
+	}
+}
+
+func TestLayoutPathsRepos(t *testing.T) {
+	g, nodeRepos := twoRepoGraph()
+	entries, slugs := AssignRepoSlugs(g.Nodes, nodeRepos)
+	paths := LayoutPaths(LayoutType, entries, BuildIndex(g.Nodes, g.Relationships))
+	if got, want := paths[slugs["b:fn"]], "b/functions/b-fn-auth-ts-login"; got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	// No top-level functions/ directory, so the index page stays put.
+	if p, ok := paths["functions"]; ok {
+		t.Errorf("index page moved to %q", p)
+	}
+}
+
+func TestTypeLayoutIndexPages(t *testing.T) {
+	g := layoutGraph()
+	idx := BuildIndex(g.Nodes, g.Relationships)
+	entries, slugs := AssignSlugs(g.Nodes)
+	for _, tt := range []struct {
+		style              LinkStyle
+		overview, fromList string
+	}{
+		{LinkPretty, `href="/functions/"`, `href="/functions/fn-helper/"`},
+		{LinkMarkdown, `href="functions/_index.md"`, `href="fn-helper.md"`},
+	} {
+		r := &Renderer{Index: idx, Slugs: slugs, Paths: LayoutPaths(LayoutType, entries, idx), LinkStyle: tt.style}
+		pages := r.RenderIndexPages(entries, GraphStats{})
+		byPath := make(map[string]string)
+		for _, p := range pages {
+			byPath[r.PagePath(p.Slug)] = p.Content
+		}
+		if !strings.Contains(byPath["overview"], tt.overview) {
+			t.Errorf("%s: overview doesn't link %s:\n%s", tt.style, tt.overview, byPath["overview"])
+		}
+		if !strings.Contains(byPath["functions/_index"], tt.fromList) {
+			t.Errorf("%s: functions index doesn't link %s:\n%s", tt.style, tt.fromList, byPath["functions/_index"])
+		}
+	}
+}
+
+func TestSanitizePath(t *testing.T) {
+	tests := map[string]string{
+		"src/auth/service.ts":  "src/auth/service.ts",
+		"/abs/../x//y/./z.go":  "abs/x/y/z.go",
+		`win\dir\file name.cs`: "win/dir/file-name.cs",
+		"../..":                "",
+		"a/<b>:c":              "a/-b--c",
+	}
+	for in, want := range tests {
+		if got := sanitizePath(in); got != want {
+			t.Errorf("sanitizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	for _, l := range Layouts {
+		if got, err := ParseLayout(string(l)); err != nil || got != l {
+			t.Errorf("ParseLayout(%q) = %q, %v", l, got, err)
+		}
+	}
+	if _, err := ParseLayout("nested"); err == nil {
+		t.Error("ParseLayout accepted an unknown layout")
+	}
+}
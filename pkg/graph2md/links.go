@@ -0,0 +1,122 @@
+package graph2md
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"strings"
+)
+
+// LinkStyle selects how internal links between pages are written.
+type LinkStyle string
+
+const (
+	// LinkHTML links to <base>/<path>.html, for sites served as .html pages.
+	LinkHTML LinkStyle = "html"
+	// LinkPretty links to <base>/<path>/, for pretty URLs (Hugo, Astro).
+	LinkPretty LinkStyle = "pretty"
+	// LinkMarkdown links relatively to <path>.md, for browsing the files
+	// directly on GitHub or in Obsidian. BasePath is ignored.
+	LinkMarkdown LinkStyle = "md"
+)
+
+// LinkStyles lists the supported link styles.
+var LinkStyles = []LinkStyle{LinkHTML, LinkPretty, LinkMarkdown}
+
+// ParseLinkStyle parses a link style name.
+func ParseLinkStyle(s string) (LinkStyle, error) {
+	for _, ls := range LinkStyles {
+		if string(ls) == s {
+			return ls, nil
+		}
+	}
+	return "", fmt.Errorf("unknown link style %q (want html, pretty or md)", s)
+}
+
+// PageURL returns the URL of the page with slug, as linked from a page at
+// the top level of the output directory.
+func (r *Renderer) PageURL(slug string) string {
+	return r.pathURL(r.PagePath(slug), "")
+}
+
+// pathURL returns the URL of the page at page path p (see PagePath). Relative
+// .md links are resolved against the page path from. With pretty links, a
+// section page is served at its directory's URL.
+func (r *Renderer) pathURL(p, from string) string {
+	base := strings.TrimSuffix(r.BasePath, "/")
+	switch r.LinkStyle {
+	case LinkPretty:
+		if dir, ok := strings.CutSuffix(p, "/"+sectionPage); ok {
+			return base + "/" + dir + "/"
+		}
+		return base + "/" + p + "/"
+	case LinkMarkdown:
+		return relativePath(path.Dir(from), p) + ".md"
+	default:
+		return base + "/" + p + ".html"
+	}
+}
+
+// relativePath returns the slash-separated path to target from directory
+// dir, both relative to the output directory.
+func relativePath(dir, target string) string {
+	if dir == "." || dir == "" {
+		return target
+	}
+	from := strings.Split(dir, "/")
+	to := strings.Split(target, "/")
+	i := 0
+	for i < len(from) && i < len(to)-1 && from[i] == to[i] {
+		i++
+	}
+	return strings.Repeat("../", len(from)-i) + strings.Join(to[i:], "/")
+}
+
+// anchor formats a link as an HTML <a> tag, or as a native [label](url)
+// Markdown link when MarkdownLinks is set.
+func (r *Renderer) anchor(url, label string) string {
+	if r.MarkdownLinks {
+		return fmt.Sprintf("[%s](%s)", markdownEscape(label), markdownURL(url))
+	}
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(label))
+}
+
+// text escapes unlinked label text for the configured output syntax.
+func (r *Renderer) text(label string) string {
+	if r.MarkdownLinks {
+		return markdownEscape(label)
+	}
+	return html.EscapeString(label)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`|`, `\|`,
+	`*`, `\*`,
+	`_`, `\_`,
+	"`", "\\`",
+	`<`, `\<`,
+	`>`, `\>`,
+)
+
+// markdownEscape backslash-escapes characters that would otherwise end a
+// link label, split a table cell, or start emphasis, code or inline HTML.
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+var markdownURLEscaper = strings.NewReplacer(
+	" ", "%20",
+	"(", "%28",
+	")", "%29",
+	"<", "%3C",
+	">", "%3E",
+)
+
+// markdownURL percent-encodes characters that would end a Markdown link
+// destination early.
+func markdownURL(s string) string {
+	return markdownURLEscaper.Replace(s)
+}
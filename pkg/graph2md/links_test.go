@@ -0,0 +1,74 @@
+package graph2md
+
+import "testing"
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		dir, target, want string
+	}{
+		{".", "functions/fn-login", "functions/fn-login"},
+		{"", "fn-login", "fn-login"},
+		{"functions", "functions/fn-save", "fn-save"},
+		{"functions", "classes/class-auth", "../classes/class-auth"},
+		{"functions", "overview", "../overview"},
+		{"src/auth/service.ts", "src/auth/service.ts/login", "login"},
+		{"src/auth/service.ts", "src/auth", "../../auth"},
+		{"src/auth", "src/index.ts", "../index.ts"},
+		{"a/b/c", "x/y", "../../../x/y"},
+		// A directory named like the target page isn't descended into.
+		{"functions", "functions", "../functions"},
+	}
+	for _, tt := range tests {
+		if got := relativePath(tt.dir, tt.target); got != tt.want {
+			t.Errorf("relativePath(%q, %q) = %q, want %q", tt.dir, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestPathURL(t *testing.T) {
+	tests := []struct {
+		style   LinkStyle
+		base    string
+		p, from string
+		want    string
+	}{
+		{LinkHTML, "", "fn-login", "", "/fn-login.html"},
+		{LinkHTML, "/docs/", "functions/fn-login", "classes/class-auth", "/docs/functions/fn-login.html"},
+		{LinkHTML, "", "functions/_index", "", "/functions/_index.html"},
+		{LinkPretty, "/docs", "functions/fn-login", "", "/docs/functions/fn-login/"},
+		{LinkPretty, "/docs", "functions/_index", "", "/docs/functions/"},
+		{LinkPretty, "", "svc-a/functions/_index", "", "/svc-a/functions/"},
+		{LinkMarkdown, "/docs", "functions/fn-login", "classes/class-auth", "../functions/fn-login.md"},
+		{LinkMarkdown, "", "functions/fn-login", "functions/_index", "fn-login.md"},
+		{LinkMarkdown, "", "functions/_index", "", "functions/_index.md"},
+		{LinkMarkdown, "", "fn-login", "fn-save", "fn-login.md"},
+	}
+	for _, tt := range tests {
+		r := &Renderer{LinkStyle: tt.style, BasePath: tt.base}
+		if got := r.pathURL(tt.p, tt.from); got != tt.want {
+			t.Errorf("%s pathURL(%q, %q) with base %q = %q, want %q", tt.style, tt.p, tt.from, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	r := &Renderer{}
+	if got, want := r.anchor("/a b.html", `x<y>`), `<a href="/a b.html">x&lt;y&gt;</a>`; got != want {
+		t.Errorf("anchor = %q, want %q", got, want)
+	}
+	r.MarkdownLinks = true
+	if got, want := r.anchor("/a b(1).html", "get_[id]"), `[get\_\[id\]](/a%20b%281%29.html)`; got != want {
+		t.Errorf("markdown anchor = %q, want %q", got, want)
+	}
+}
+
+func TestParseLinkStyle(t *testing.T) {
+	for _, ls := range LinkStyles {
+		if got, err := ParseLinkStyle(string(ls)); err != nil || got != ls {
+			t.Errorf("ParseLinkStyle(%q) = %q, %v", ls, got, err)
+		}
+	}
+	if _, err := ParseLinkStyle("relative"); err == nil {
+		t.Error("ParseLinkStyle accepted an unknown style")
+	}
+}
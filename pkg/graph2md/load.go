@@ -0,0 +1,311 @@
+package graph2md
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// LoadGraph reads a graph JSON file and returns its nodes and relationships.
+// It accepts a full APIResponse envelope, a bare GraphResult, or a bare Graph.
+func LoadGraph(path string) ([]Node, []Relationship, error) {
+	result, err := Load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Graph.Nodes, result.Graph.Relationships, nil
+}
+
+// Load reads a graph JSON file like LoadGraph, but returns the whole
+// GraphResult so callers can use its metadata. A bare Graph is wrapped in
+// an otherwise empty GraphResult. The path "-" reads standard input.
+//
+// An APIResponse's result is returned whatever its status; use
+// LoadResponse to check the status too.
+func Load(path string) (*GraphResult, error) {
+	resp, err := LoadResponse(path)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Result == nil {
+		return nil, resp.Err()
+	}
+	return resp.Result, nil
+}
+
+// LoadResponse reads a graph JSON file like Load, but returns the whole
+// APIResponse envelope. A bare GraphResult or Graph is wrapped in an
+// envelope with no status.
+func LoadResponse(path string) (*APIResponse, error) {
+	if path == "-" {
+		return DecodeResponse(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if fi, err := f.Stat(); err == nil {
+		log.Printf("  File size: %d bytes", fi.Size())
+	}
+	return DecodeResponse(f)
+}
+
+// Decode reads a graph in any format Load accepts from r, like Load.
+func Decode(r io.Reader) (*GraphResult, error) {
+	resp, err := DecodeResponse(r)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Result == nil {
+		return nil, resp.Err()
+	}
+	return resp.Result, nil
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// DecodeResponse reads a graph in any format LoadResponse accepts from r,
+// decompressing gzip input, which is recognized by its magic bytes. The
+// input is streamed: the envelope is recognized from the top-level keys as
+// they are read, and nodes and relationships are decoded one at a time, so
+// the raw JSON is never held in memory.
+func DecodeResponse(r io.Reader) (*APIResponse, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading gzip input: %w", err)
+		}
+		defer zr.Close()
+		br = bufio.NewReaderSize(zr, 1<<20)
+	case bytes.HasPrefix(magic, zstdMagic):
+		return nil, fmt.Errorf("zstd-compressed input is not supported; decompress it first (zstd -dc) or use gzip")
+	}
+	d := &decoder{json.NewDecoder(br)}
+
+	// The top level holds the keys of exactly one of the three formats;
+	// they are all decoded into one place and told apart afterwards.
+	var resp APIResponse
+	var result GraphResult
+	var isResponse, hasResult, isResult, isGraph bool
+	err := d.object(func(key string) error {
+		switch key {
+		case "status":
+			isResponse = true
+			return d.Decode(&resp.Status)
+		case "jobId":
+			isResponse = true
+			return d.Decode(&resp.JobID)
+		case "error":
+			isResponse = true
+			return d.Decode(&resp.Error)
+		case "result":
+			isResponse = true
+			null, err := d.graphResult(&result)
+			hasResult = !null
+			return err
+		case "nodes", "relationships":
+			isGraph = true
+			return d.graphField(&result.Graph, key)
+		}
+		ok, err := d.graphResultField(&result, key)
+		if ok {
+			isResult = true
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decoding graph JSON: %w", err)
+	}
+
+	g := result.Graph
+	switch {
+	case isResponse && hasResult:
+		log.Printf("  APIResponse parsed: %d nodes, %d rels", len(g.Nodes), len(g.Relationships))
+		resp.Result = &result
+		return &resp, nil
+	case isResponse:
+		return &resp, nil
+	case (isResult || isGraph) && len(g.Nodes) > 0:
+		return &APIResponse{Result: &result}, nil
+	}
+	return nil, fmt.Errorf("unrecognized graph format")
+}
+
+// decoder reads JSON values token by token.
+type decoder struct {
+	*json.Decoder
+}
+
+// object reads an object, calling field for each key with the decoder
+// positioned at its value, which field must consume. A null value is
+// treated as an empty object.
+func (d *decoder) object(field func(key string) error) error {
+	if _, null, err := d.open('{'); err != nil || null {
+		return err
+	}
+	for d.More() {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if err := field(tok.(string)); err != nil {
+			return err
+		}
+	}
+	_, err := d.Token() // '}'
+	return err
+}
+
+// array reads an array, calling elem with the decoder positioned at each
+// element, which elem must consume. A null value is treated as empty.
+func (d *decoder) array(elem func() error) error {
+	if _, null, err := d.open('['); err != nil || null {
+		return err
+	}
+	for d.More() {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	_, err := d.Token() // ']'
+	return err
+}
+
+// open reads the opening delimiter of a value, reporting whether the value
+// is null instead.
+func (d *decoder) open(want json.Delim) (json.Delim, bool, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return 0, false, err
+	}
+	if tok == nil {
+		return 0, true, nil
+	}
+	if delim, ok := tok.(json.Delim); ok && delim == want {
+		return delim, false, nil
+	}
+	return 0, false, fmt.Errorf("expected %q at offset %d, found %v", want, d.InputOffset(), tok)
+}
+
+// skip consumes the next value without decoding it.
+func (d *decoder) skip() error {
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// graphResult reads a GraphResult object, reporting whether it was null.
+func (d *decoder) graphResult(result *GraphResult) (null bool, err error) {
+	null = true
+	err = d.object(func(key string) error {
+		null = false
+		_, err := d.graphResultField(result, key)
+		return err
+	})
+	return null, err
+}
+
+// graphResultField decodes the GraphResult field named key, reporting
+// whether key is one. Unknown fields are skipped.
+func (d *decoder) graphResultField(result *GraphResult, key string) (bool, error) {
+	switch key {
+	case "generatedAt":
+		return true, d.Decode(&result.GeneratedAt)
+	case "message":
+		return true, d.Decode(&result.Message)
+	case "stats":
+		return true, d.Decode(&result.Stats)
+	case "metadata":
+		return true, d.Decode(&result.Metadata)
+	case "domains":
+		return true, d.Decode(&result.Domains)
+	case "artifacts":
+		return true, d.Decode(&result.Artifacts)
+	case "graph":
+		return true, d.object(func(key string) error {
+			return d.graphField(&result.Graph, key)
+		})
+	}
+	return false, d.skip()
+}
+
+// graphField decodes the Graph field named key, streaming nodes and
+// relationships one element at a time.
+func (d *decoder) graphField(g *Graph, key string) error {
+	switch key {
+	case "nodes":
+		return d.array(func() error {
+			var n Node
+			if err := d.Decode(&n); err != nil {
+				return err
+			}
+			g.Nodes = append(g.Nodes, n)
+			return nil
+		})
+	case "relationships":
+		return d.array(func() error {
+			var rel Relationship
+			if err := d.Decode(&rel); err != nil {
+				return err
+			}
+			g.Relationships = append(g.Relationships, rel)
+			return nil
+		})
+	}
+	return d.skip()
+}
+
+// commitKeys are the metadata fields that may hold the analyzed commit.
+var commitKeys = []string{"commit", "commitSha", "commitHash", "sha", "revision"}
+
+// Commit returns the commit the graph was generated from, read from a
+// commit-like field in Metadata (top level or under "git" or "repository"),
+// or "" if there is none.
+func (g *GraphResult) Commit() string {
+	if len(g.Metadata) == 0 {
+		return ""
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal(g.Metadata, &meta); err != nil {
+		return ""
+	}
+	for _, scope := range []interface{}{meta, meta["git"], meta["repository"]} {
+		m, ok := scope.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, k := range commitKeys {
+			if s := strings.TrimSpace(getStr(m, k)); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package graph2md
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	const graph = `{"nodes": [{"id": "f1", "labels": ["File"], "properties": {"path": "a.ts"}}, {"id": "f2", "labels": ["File"], "properties": {"path": "b.ts"}}],
+		"relationships": [{"id": "r1", "type": "IMPORTS", "startNode": "f1", "endNode": "f2"}]}`
+	envelope := `{"status": "completed", "jobId": "j1", "error": null, "result": {"generatedAt": "2026-01-02T03:04:05Z", "graph": ` + graph + `}}`
+
+	tests := []struct {
+		name    string
+		input   string
+		gzip    bool
+		status  string
+		jobID   string
+		result  bool // whether a result is returned
+		nodes   int
+		rels    int
+		genAt   string
+		wantErr string
+	}{
+		{name: "envelope", input: envelope, status: "completed", jobID: "j1", result: true, nodes: 2, rels: 1, genAt: "2026-01-02T03:04:05Z"},
+		{name: "null result", input: `{"status": "pending", "jobId": "j2", "result": null}`, status: "pending", jobID: "j2"},
+		{name: "envelope without result", input: `{"status": "failed", "error": {"message": "boom"}}`, status: "failed"},
+		{name: "bare GraphResult", input: `{"generatedAt": "2026-01-02T03:04:05Z", "message": "ok", "graph": ` + graph + `}`, result: true, nodes: 2, rels: 1, genAt: "2026-01-02T03:04:05Z"},
+		{name: "bare Graph", input: graph, result: true, nodes: 2, rels: 1},
+		{name: "unknown keys", input: `{"version": 2, "extra": {"nested": [1, {"a": null}]}, "graph": {"schema": "v1", "nodes": [{"id": "f1", "labels": ["File"]}]}, "trailer": "x"}`, result: true, nodes: 1},
+		{name: "gzip", input: envelope, gzip: true, status: "completed", jobID: "j1", result: true, nodes: 2, rels: 1, genAt: "2026-01-02T03:04:05Z"},
+		{name: "truncated", input: envelope[:len(envelope)/2], wantErr: "decoding graph JSON"},
+		{name: "truncated gzip", input: envelope, gzip: true, wantErr: "unexpected EOF"},
+		{name: "empty graph", input: `{"nodes": []}`, wantErr: "unrecognized graph format"},
+		{name: "unrecognized", input: `{"foo": 1}`, wantErr: "unrecognized graph format"},
+		{name: "not an object", input: `[1, 2]`, wantErr: "decoding graph JSON"},
+		{name: "zstd", input: "\x28\xb5\x2f\xfd rest", wantErr: "zstd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.input)
+			if tt.gzip {
+				var buf bytes.Buffer
+				zw := gzip.NewWriter(&buf)
+				zw.Write(data)
+				zw.Close()
+				data = buf.Bytes()
+				if tt.wantErr != "" {
+					data = data[:len(data)/2]
+				}
+			}
+			resp, err := DecodeResponse(bytes.NewReader(data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DecodeResponse error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != tt.status || resp.JobID != tt.jobID {
+				t.Errorf("status, job = %q, %q; want %q, %q", resp.Status, resp.JobID, tt.status, tt.jobID)
+			}
+			if (resp.Result != nil) != tt.result {
+				t.Fatalf("result = %v, want one: %t", resp.Result, tt.result)
+			}
+			if resp.Result == nil {
+				return
+			}
+			g := resp.Result.Graph
+			if len(g.Nodes) != tt.nodes || len(g.Relationships) != tt.rels || resp.Result.GeneratedAt != tt.genAt {
+				t.Errorf("got %d nodes, %d relationships, generated at %q; want %d, %d, %q",
+					len(g.Nodes), len(g.Relationships), resp.Result.GeneratedAt, tt.nodes, tt.rels, tt.genAt)
+			}
+		})
+	}
+}
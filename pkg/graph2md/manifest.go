@@ -0,0 +1,157 @@
+package graph2md
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Manifest lists every page graph2md generated, for site builders and
+// search indexers that would otherwise re-parse each page's frontmatter.
+type Manifest struct {
+	Generator   string         `json:"generator"`
+	Repo        string         `json:"repo,omitempty"`
+	Commit      string         `json:"commit,omitempty"`
+	GeneratedAt string         `json:"generated_at,omitempty"`
+	Pages       []ManifestPage `json:"pages"`
+
+	// Stale lists pages generated by earlier runs that are no longer in
+	// the graph but were not pruned, so a later prune still recognizes them.
+	Stale []ManifestPage `json:"stale,omitempty"`
+}
+
+// ManifestPage describes one generated page.
+type ManifestPage struct {
+	Slug       string   `json:"slug"`
+	NodeID     string   `json:"node_id,omitempty"`
+	NodeType   string   `json:"node_type"`
+	Repo       string   `json:"repo,omitempty"` // in sites merged from several repositories
+	Title      string   `json:"title"`
+	Domain     string   `json:"domain,omitempty"`
+	Subdomain  string   `json:"subdomain,omitempty"`
+	FilePath   string   `json:"file_path,omitempty"`
+	OutputPath string   `json:"output_path"`
+	Hash       string   `json:"hash,omitempty"`    // SHA-256 of the page content
+	Aliases    []string `json:"aliases,omitempty"` // former page paths, without ".md"
+}
+
+// NewManifest returns an empty manifest for the renderer's repo and commit.
+func (r *Renderer) NewManifest() *Manifest {
+	return &Manifest{
+		Generator:   "graph2md",
+		Repo:        r.RepoName,
+		Commit:      r.Commit,
+		GeneratedAt: r.GeneratedAt,
+	}
+}
+
+// EntryPage describes an entity page rendered as content and written to
+// outputPath.
+func (r *Renderer) EntryPage(e Entry, content, outputPath string) ManifestPage {
+	props := e.Node.Properties
+	p := ManifestPage{
+		Slug:       e.Slug,
+		NodeID:     e.Node.ID,
+		NodeType:   e.Label,
+		Repo:       e.Repo,
+		Title:      frontmatterString(content, "title"),
+		Domain:     r.NodeName(r.BelongsToDomain[e.Node.ID]),
+		Subdomain:  r.NodeName(r.BelongsToSubdomain[e.Node.ID]),
+		FilePath:   getStr(props, "filePath"),
+		OutputPath: outputPath,
+		Hash:       ContentHash(content),
+		Aliases:    r.Aliases[e.Node.ID],
+	}
+	switch e.Label {
+	case "File", "Directory":
+		p.FilePath = getStr(props, "path")
+	case "Domain":
+		p.Domain = getStr(props, "name")
+	case "Subdomain":
+		p.Subdomain = getStr(props, "name")
+		p.Domain = r.NodeName(r.PartOfDomain[e.Node.ID])
+	}
+	return p
+}
+
+// IndexPage describes an overview or index page written to outputPath.
+func (r *Renderer) IndexPage(p Page, outputPath string) ManifestPage {
+	return ManifestPage{
+		Slug:       p.Slug,
+		NodeType:   frontmatterString(p.Content, "node_type"),
+		Title:      frontmatterString(p.Content, "title"),
+		OutputPath: outputPath,
+		Hash:       ContentHash(p.Content),
+	}
+}
+
+// ReadManifest reads a manifest written by a previous run.
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Write writes the manifest as indented JSON, with pages sorted by slug.
+// An identical existing file is left untouched.
+func (m *Manifest) Write(path string) error {
+	sort.Slice(m.Pages, func(i, j int) bool { return m.Pages[i].Slug < m.Pages[j].Slug })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Missing returns the pages (and stale pages) of prev that are not in m,
+// by output path.
+func (m *Manifest) Missing(prev *Manifest) []ManifestPage {
+	if prev == nil {
+		return nil
+	}
+	current := make(map[string]bool, len(m.Pages))
+	for _, p := range m.Pages {
+		current[p.OutputPath] = true
+	}
+	var missing []ManifestPage
+	for _, p := range append(prev.Pages[:len(prev.Pages):len(prev.Pages)], prev.Stale...) {
+		if !current[p.OutputPath] {
+			current[p.OutputPath] = true
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// frontmatterString returns the quoted string value of a top-level key in
+// a page's frontmatter, or "" if it is absent.
+func frontmatterString(content, key string) string {
+	content, ok := strings.CutPrefix(content, "---\n")
+	if !ok {
+		return ""
+	}
+	fm, _, _ := strings.Cut(content, "\n---\n")
+	for _, line := range strings.Split(fm, "\n") {
+		if v, ok := strings.CutPrefix(line, key+": "); ok {
+			if s, err := strconv.Unquote(v); err == nil {
+				return s
+			}
+			return v
+		}
+	}
+	return ""
+}
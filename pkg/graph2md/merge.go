@@ -0,0 +1,240 @@
+package graph2md
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MergePolicy selects how the properties of a node found in several input
+// graphs are combined.
+type MergePolicy string
+
+const (
+	// MergeFirst keeps the node as first seen.
+	MergeFirst MergePolicy = "first"
+	// MergeLast replaces the node with each later copy.
+	MergeLast MergePolicy = "last"
+	// MergeDeep merges properties recursively, later values winning, and
+	// unions labels.
+	MergeDeep MergePolicy = "deep"
+)
+
+// MergePolicies lists the supported merge policies.
+var MergePolicies = []MergePolicy{MergeFirst, MergeLast, MergeDeep}
+
+// ParseMergePolicy parses a merge policy name.
+func ParseMergePolicy(s string) (MergePolicy, error) {
+	for _, p := range MergePolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown merge policy %q (want first, last or deep)", s)
+}
+
+// Conflict records a node property whose value differs between inputs.
+type Conflict struct {
+	NodeID   string      `json:"node_id"`
+	Property string      `json:"property"` // dotted path for nested values
+	Existing interface{} `json:"existing"`
+	From     string      `json:"from"` // input the existing value came from
+	Incoming interface{} `json:"incoming"`
+	Input    string      `json:"input"` // input the incoming value came from
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("node %s property %q: %v (%s) vs %v (%s)", c.NodeID, c.Property, c.Existing, c.From, c.Incoming, c.Input)
+}
+
+// Merger combines graphs from several inputs. Nodes are deduplicated by ID
+// and combined by Policy; relationships are deduplicated by type and
+// endpoints, so overlapping graphs don't produce repeated edges. The input
+// graphs are not modified.
+type Merger struct {
+	Policy MergePolicy
+
+	nodes     []Node
+	byID      map[string]int
+	sources   map[string]map[string]string // node ID -> property path -> input
+	rels      []Relationship
+	relKeys   map[string]bool
+	relIDs    map[string]bool
+	dupRels   int
+	idClashes int
+	conflicts []Conflict
+}
+
+// NewMerger returns an empty Merger using policy.
+func NewMerger(policy MergePolicy) *Merger {
+	return &Merger{
+		Policy:  policy,
+		byID:    make(map[string]int),
+		sources: make(map[string]map[string]string),
+		relKeys: make(map[string]bool),
+		relIDs:  make(map[string]bool),
+	}
+}
+
+// Add merges graph g, read from input, into the result.
+func (m *Merger) Add(input string, g Graph) {
+	for _, n := range g.Nodes {
+		i, ok := m.byID[n.ID]
+		if !ok {
+			m.byID[n.ID] = len(m.nodes)
+			m.nodes = append(m.nodes, n)
+			m.sources[n.ID] = map[string]string{"": input}
+			continue
+		}
+		m.mergeNode(&m.nodes[i], n, input)
+	}
+
+	for _, rel := range g.Relationships {
+		key := rel.Type + "\x00" + rel.StartNode + "\x00" + rel.EndNode
+		if m.relKeys[key] {
+			m.dupRels++
+			continue
+		}
+		m.relKeys[key] = true
+		if rel.ID != "" {
+			if m.relIDs[rel.ID] {
+				m.idClashes++
+			}
+			m.relIDs[rel.ID] = true
+		}
+		m.rels = append(m.rels, rel)
+	}
+}
+
+func (m *Merger) mergeNode(dst *Node, src Node, input string) {
+	from := m.sources[src.ID]
+	m.diff(src.ID, "", dst.Properties, src.Properties, from, input)
+
+	switch m.Policy {
+	case MergeLast:
+		*dst = src
+		m.sources[src.ID] = map[string]string{"": input}
+	case MergeDeep:
+		// dst may still share its labels and properties with an input.
+		dst.Labels = slices.Clip(dst.Labels)
+		for _, l := range src.Labels {
+			if !slices.Contains(dst.Labels, l) {
+				dst.Labels = append(dst.Labels, l)
+			}
+		}
+		dst.Properties = copyProps(dst.Properties)
+		if dst.Properties == nil && src.Properties != nil {
+			dst.Properties = make(map[string]interface{}, len(src.Properties))
+		}
+		deepMerge(dst.Properties, src.Properties, "", from, input)
+	}
+}
+
+// diff records a conflict for every property path whose value differs
+// between the merged node and an incoming copy.
+func (m *Merger) diff(nodeID, prefix string, existing, incoming map[string]interface{}, from map[string]string, input string) {
+	keys := make([]string, 0, len(incoming))
+	for k := range incoming {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		old, ok := existing[k]
+		if !ok {
+			continue
+		}
+		path := prefix + k
+		newVal := incoming[k]
+		oldMap, oldIsMap := old.(map[string]interface{})
+		newMap, newIsMap := newVal.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			m.diff(nodeID, path+".", oldMap, newMap, from, input)
+			continue
+		}
+		if !reflect.DeepEqual(old, newVal) {
+			m.conflicts = append(m.conflicts, Conflict{
+				NodeID:   nodeID,
+				Property: path,
+				Existing: old,
+				From:     sourceOf(from, path),
+				Incoming: newVal,
+				Input:    input,
+			})
+		}
+	}
+}
+
+// deepMerge copies src into dst, merging nested objects, and records which
+// input each overwritten path now comes from.
+func deepMerge(dst, src map[string]interface{}, prefix string, from map[string]string, input string) {
+	for k, v := range src {
+		path := prefix + k
+		if dm, ok := dst[k].(map[string]interface{}); ok {
+			if sm, ok := v.(map[string]interface{}); ok {
+				deepMerge(dm, sm, path+".", from, input)
+				continue
+			}
+		}
+		if sm, ok := v.(map[string]interface{}); ok {
+			v = copyProps(sm)
+		}
+		dst[k] = v
+		from[path] = input
+	}
+}
+
+// copyProps returns a copy of props and the objects nested in it.
+func copyProps(props map[string]interface{}) map[string]interface{} {
+	if props == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = copyProps(m)
+		}
+		c[k] = v
+	}
+	return c
+}
+
+// sourceOf returns the input the value at path was taken from: the input
+// recorded for the path or its nearest parent, else the node's first input.
+func sourceOf(from map[string]string, path string) string {
+	for p := path; ; {
+		if in, ok := from[p]; ok {
+			return in
+		}
+		i := strings.LastIndexByte(p, '.')
+		if i < 0 {
+			return from[""]
+		}
+		p = p[:i]
+	}
+}
+
+// Graph returns the merged graph, with nodes and relationships in the order
+// they were first seen.
+func (m *Merger) Graph() Graph {
+	return Graph{Nodes: m.nodes, Relationships: m.rels}
+}
+
+// Conflicts returns the node property conflicts found between inputs, in
+// the order they were found.
+func (m *Merger) Conflicts() []Conflict {
+	return m.conflicts
+}
+
+// DuplicateRelationships returns how many relationships were dropped as
+// duplicates.
+func (m *Merger) DuplicateRelationships() int {
+	return m.dupRels
+}
+
+// RelationshipIDCollisions returns how many relationships were kept although
+// an earlier, different relationship had the same ID.
+func (m *Merger) RelationshipIDCollisions() int {
+	return m.idClashes
+}
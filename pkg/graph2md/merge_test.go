@@ -0,0 +1,165 @@
+package graph2md
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func mergeInputs() (a, b Graph) {
+	a = Graph{
+		Nodes: []Node{
+			{ID: "fn1", Labels: []string{"Function"}, Properties: map[string]interface{}{
+				"name": "login", "startLine": 10.0,
+				"meta": map[string]interface{}{"owner": "auth", "since": "v1"},
+			}},
+			testNode("fn2", "Function", "name", "save"),
+		},
+		Relationships: []Relationship{
+			testRel("r1", "calls", "fn1", "fn2"),
+		},
+	}
+	b = Graph{
+		Nodes: []Node{
+			{ID: "fn1", Labels: []string{"Function", "Exported"}, Properties: map[string]interface{}{
+				"startLine": 12.0, "language": "TypeScript",
+				"meta": map[string]interface{}{"owner": "identity"},
+			}},
+			testNode("fn3", "Function", "name", "load"),
+		},
+		Relationships: []Relationship{
+			testRel("r9", "calls", "fn1", "fn2"), // same edge, other ID
+			testRel("r1", "calls", "fn2", "fn3"), // other edge, same ID
+			testRel("r3", "calls", "fn2", "fn3"),
+		},
+	}
+	return a, b
+}
+
+func TestMergerPolicies(t *testing.T) {
+	tests := []struct {
+		policy MergePolicy
+		labels []string
+		props  map[string]interface{}
+	}{
+		{MergeFirst, []string{"Function"}, map[string]interface{}{
+			"name": "login", "startLine": 10.0,
+			"meta": map[string]interface{}{"owner": "auth", "since": "v1"},
+		}},
+		{MergeLast, []string{"Function", "Exported"}, map[string]interface{}{
+			"startLine": 12.0, "language": "TypeScript",
+			"meta": map[string]interface{}{"owner": "identity"},
+		}},
+		{MergeDeep, []string{"Function", "Exported"}, map[string]interface{}{
+			"name": "login", "startLine": 12.0, "language": "TypeScript",
+			"meta": map[string]interface{}{"owner": "identity", "since": "v1"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			a, b := mergeInputs()
+			m := NewMerger(tt.policy)
+			m.Add("a.json", a)
+			m.Add("b.json", b)
+			g := m.Graph()
+
+			var ids []string
+			for _, n := range g.Nodes {
+				ids = append(ids, n.ID)
+			}
+			if want := []string{"fn1", "fn2", "fn3"}; !slices.Equal(ids, want) {
+				t.Fatalf("nodes = %v, want %v", ids, want)
+			}
+			fn1 := g.Nodes[0]
+			if !slices.Equal(fn1.Labels, tt.labels) {
+				t.Errorf("labels = %v, want %v", fn1.Labels, tt.labels)
+			}
+			if !reflect.DeepEqual(fn1.Properties, tt.props) {
+				t.Errorf("properties = %v, want %v", fn1.Properties, tt.props)
+			}
+
+			// Edges are deduplicated by type and endpoints; a different
+			// edge reusing an ID is kept and counted.
+			var rels []string
+			for _, r := range g.Relationships {
+				rels = append(rels, r.ID+" "+r.StartNode+"->"+r.EndNode)
+			}
+			if want := []string{"r1 fn1->fn2", "r1 fn2->fn3"}; !slices.Equal(rels, want) {
+				t.Errorf("relationships = %v, want %v", rels, want)
+			}
+			if got := m.DuplicateRelationships(); got != 2 {
+				t.Errorf("DuplicateRelationships = %d, want 2", got)
+			}
+			if got := m.RelationshipIDCollisions(); got != 1 {
+				t.Errorf("RelationshipIDCollisions = %d, want 1", got)
+			}
+
+			// Conflicts are the same whatever the policy.
+			want := []Conflict{
+				{NodeID: "fn1", Property: "meta.owner", Existing: "auth", From: "a.json", Incoming: "identity", Input: "b.json"},
+				{NodeID: "fn1", Property: "startLine", Existing: 10.0, From: "a.json", Incoming: 12.0, Input: "b.json"},
+			}
+			if got := m.Conflicts(); !reflect.DeepEqual(got, want) {
+				t.Errorf("conflicts = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMergeDeepLeavesInputsAlone(t *testing.T) {
+	a, b := mergeInputs()
+	b.Nodes = append(b.Nodes, Node{ID: "fn2", Labels: []string{"Function", "Async"}, Properties: map[string]interface{}{
+		"retry": map[string]interface{}{"attempts": 3.0},
+	}})
+	c := Graph{Nodes: []Node{{ID: "fn2", Properties: map[string]interface{}{
+		"retry": map[string]interface{}{"backoff": "exponential"},
+	}}}}
+	wantA, wantB := mergeInputs()
+
+	m := NewMerger(MergeDeep)
+	m.Add("a.json", a)
+	m.Add("b.json", b)
+	m.Add("c.json", c)
+
+	if !reflect.DeepEqual(a, wantA) {
+		t.Errorf("first input changed by merging:\n%v\nwant\n%v", a, wantA)
+	}
+	if !reflect.DeepEqual(b.Nodes[:2], wantB.Nodes) {
+		t.Errorf("second input changed by merging:\n%v\nwant\n%v", b.Nodes[:2], wantB.Nodes)
+	}
+	if retry := b.Nodes[2].Properties["retry"]; !reflect.DeepEqual(retry, map[string]interface{}{"attempts": 3.0}) {
+		t.Errorf("nested object of the second input = %v, want it unchanged", retry)
+	}
+	fn2 := m.Graph().Nodes[1]
+	if want := map[string]interface{}{"attempts": 3.0, "backoff": "exponential"}; !reflect.DeepEqual(fn2.Properties["retry"], want) {
+		t.Errorf("merged retry = %v, want %v", fn2.Properties["retry"], want)
+	}
+}
+
+func TestMergerConflictSource(t *testing.T) {
+	node := func(owner string) Graph {
+		return Graph{Nodes: []Node{{ID: "n", Labels: []string{"File"}, Properties: map[string]interface{}{
+			"meta": map[string]interface{}{"owner": owner},
+		}}}}
+	}
+	m := NewMerger(MergeDeep)
+	m.Add("a.json", node("a"))
+	m.Add("b.json", node("b"))
+	m.Add("c.json", node("c"))
+	// The third input conflicts with the value the second one set.
+	got := m.Conflicts()
+	if len(got) != 2 || got[1].From != "b.json" || got[1].Existing != "b" || got[1].Input != "c.json" {
+		t.Errorf("conflicts = %v, want the second from b.json to c.json", got)
+	}
+}
+
+func TestParseMergePolicy(t *testing.T) {
+	for _, p := range MergePolicies {
+		if got, err := ParseMergePolicy(string(p)); err != nil || got != p {
+			t.Errorf("ParseMergePolicy(%q) = %q, %v", p, got, err)
+		}
+	}
+	if _, err := ParseMergePolicy("newest"); err == nil {
+		t.Error("ParseMergePolicy accepted an unknown policy")
+	}
+}
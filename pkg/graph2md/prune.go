@@ -0,0 +1,138 @@
+package graph2md
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// generatorMarker is the frontmatter line that marks a page as written by
+// graph2md. Pages without it are never pruned unless the previous manifest
+// vouches for them.
+const generatorMarker = `generator: "graph2md"`
+
+// IsGenerated reports whether page content carries the graph2md ownership
+// marker in its frontmatter.
+func IsGenerated(content []byte) bool {
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return false
+	}
+	fm, _, _ := bytes.Cut(content[4:], []byte("\n---\n"))
+	for _, line := range bytes.Split(fm, []byte("\n")) {
+		if string(line) == generatorMarker {
+			return true
+		}
+	}
+	return false
+}
+
+// StalePages returns the pages under dir that graph2md generated on an
+// earlier run and that are not part of current, sorted by output path. A
+// page counts as ours if the previous manifest lists it and it is unchanged
+// since (its hash still matches), or if it carries the generator marker.
+// Hand-written files are ignored; generated pages edited by hand are
+// returned separately as modified, with their originally recorded hash, so
+// they are never pruned. Pages under skipDirs, such as an archive directory
+// inside dir, are left out.
+func StalePages(dir string, current, prev *Manifest, skipDirs ...string) (stale, modified []ManifestPage, err error) {
+	skip := make(map[string]bool, len(skipDirs))
+	for _, d := range skipDirs {
+		if abs, err := filepath.Abs(d); err == nil {
+			skip[abs] = true
+		}
+	}
+	keep := make(map[string]bool, len(current.Pages))
+	for _, p := range current.Pages {
+		keep[filepath.ToSlash(p.OutputPath)] = true
+	}
+	found := make(map[string]ManifestPage)
+
+	for _, p := range current.Missing(prev) {
+		rel := filepath.ToSlash(p.OutputPath)
+		if keep[rel] || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			continue // already gone
+		}
+		if p.Hash != "" && ContentHash(string(data)) != p.Hash {
+			modified = append(modified, p)
+			keep[rel] = true
+			continue
+		}
+		if p.Hash == "" && !IsGenerated(data) {
+			continue
+		}
+		found[rel] = p
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if abs, err := filepath.Abs(path); err == nil && skip[abs] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if _, ok := found[rel]; ok || keep[rel] {
+			return nil
+		}
+		if data, err := os.ReadFile(path); err == nil && IsGenerated(data) {
+			found[rel] = ManifestPage{OutputPath: rel, Hash: ContentHash(string(data))}
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	for _, p := range found {
+		stale = append(stale, p)
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].OutputPath < stale[j].OutputPath })
+	sort.Slice(modified, func(i, j int) bool { return modified[i].OutputPath < modified[j].OutputPath })
+	return stale, modified, nil
+}
+
+// PrunePages removes the given pages from dir, or moves them under
+// archiveDir (keeping their relative paths) when archiveDir is set.
+// Directories left empty are removed.
+func PrunePages(dir string, pages []ManifestPage, archiveDir string) error {
+	for _, p := range pages {
+		rel := filepath.ToSlash(p.OutputPath)
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if archiveDir != "" {
+			dest := filepath.Join(archiveDir, filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				return err
+			}
+			if err := os.Rename(path, dest); err != nil {
+				return err
+			}
+		} else if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		// Remove parent directories the page leaves empty.
+		for parent := filepath.Dir(path); parent != filepath.Clean(dir); parent = filepath.Dir(parent) {
+			if os.Remove(parent) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package graph2md
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func outputPaths(pages []ManifestPage) []string {
+	paths := make([]string, len(pages))
+	for i, p := range pages {
+		paths[i] = p.OutputPath
+	}
+	return paths
+}
+
+func TestStalePages(t *testing.T) {
+	generated := func(title string) string {
+		return "---\ntitle: \"" + title + "\"\n" + generatorMarker + "\n---\n\nBody.\n"
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"current.md":             generated("current"),
+		"gone.md":                generated("gone"),
+		"functions/gone.md":      generated("nested"),
+		"edited.md":              generated("edited") + "A note added by hand.\n",
+		"listed.md":              "---\ntitle: \"listed\"\n---\n",
+		"handwritten.md":         "---\ntitle: \"about\"\n---\n\nWritten by hand.\n",
+		"notes.txt":              generated("not a page"),
+		"archive/old.md":         generated("archived"),
+		"archive/nested/more.md": generated("archived"),
+	})
+	current := &Manifest{Pages: []ManifestPage{{OutputPath: "current.md"}}}
+	prev := &Manifest{Pages: []ManifestPage{
+		{OutputPath: "current.md"},
+		{OutputPath: "edited.md", Hash: ContentHash(generated("edited"))},
+		// Listed without a hash, as by older manifests, and without the
+		// marker: not provably ours.
+		{OutputPath: "listed.md"},
+		{OutputPath: "../outside.md"},
+		{OutputPath: "deleted.md", Hash: "abc"},
+	}}
+
+	stale, modified, err := StalePages(dir, current, prev, filepath.Join(dir, "archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := outputPaths(stale), []string{"functions/gone.md", "gone.md"}; !slices.Equal(got, want) {
+		t.Errorf("stale = %v, want %v", got, want)
+	}
+	if got, want := outputPaths(modified), []string{"edited.md"}; !slices.Equal(got, want) {
+		t.Errorf("modified = %v, want %v", got, want)
+	}
+
+	// Without skipping it, archived pages look stale.
+	stale, _, err = StalePages(dir, current, prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(outputPaths(stale), "archive/old.md") {
+		t.Errorf("stale = %v, want archived pages when the archive isn't skipped", outputPaths(stale))
+	}
+}
+
+func TestStalePagesMissingDir(t *testing.T) {
+	stale, modified, err := StalePages(filepath.Join(t.TempDir(), "none"), &Manifest{}, nil)
+	if err != nil || len(stale) != 0 || len(modified) != 0 {
+		t.Errorf("StalePages(missing dir) = %v, %v, %v; want nothing", stale, modified, err)
+	}
+}
+
+func TestPrunePages(t *testing.T) {
+	for _, archive := range []bool{false, true} {
+		name := "delete"
+		if archive {
+			name = "archive"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				"gone.md":              "gone",
+				"functions/a/gone.md":  "nested",
+				"functions/kept.md":    "kept",
+				"classes/only/gone.md": "only",
+			})
+			archiveDir := ""
+			if archive {
+				archiveDir = filepath.Join(t.TempDir(), "archive")
+			}
+			pages := []ManifestPage{{OutputPath: "gone.md"}, {OutputPath: "functions/a/gone.md"}, {OutputPath: "classes/only/gone.md"}, {OutputPath: "already-gone.md"}}
+			err := PrunePages(dir, pages[:3], archiveDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, rel := range []string{"gone.md", "functions/a", "classes"} {
+				if _, err := os.Stat(filepath.Join(dir, rel)); !os.IsNotExist(err) {
+					t.Errorf("%s still exists", rel)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dir, "functions", "kept.md")); err != nil {
+				t.Errorf("kept page: %v", err)
+			}
+			if archive {
+				data, err := os.ReadFile(filepath.Join(archiveDir, "functions", "a", "gone.md"))
+				if err != nil || string(data) != "nested" {
+					t.Errorf("archived page = %q, %v", data, err)
+				}
+			} else if err := PrunePages(dir, pages[3:], ""); err != nil {
+				t.Errorf("pruning a page that is already gone: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package graph2md
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Query selects entities. Empty fields match any entity. Name and Path are
+// shell patterns as in path.Match; Path matches a file's or directory's own
+// path, or the file other entities are defined in, and also matches
+// everything under a directory given as a plain prefix. Type and Domain are
+// compared case-insensitively.
+type Query struct {
+	ID     string
+	Type   string
+	Name   string
+	Path   string
+	Domain string
+}
+
+// Match is an entity selected by a Query.
+type Match struct {
+	EntityRef
+	Slug      string `json:"slug"`
+	Repo      string `json:"repo,omitempty"`
+	Domain    string `json:"domain,omitempty"`
+	Subdomain string `json:"subdomain,omitempty"`
+}
+
+// Find returns the entries matching q, ordered by type, path and name. It
+// returns path.ErrBadPattern if Name or Path is malformed.
+func (q Query) Find(entries []Entry, idx *Index) ([]Match, error) {
+	for _, p := range []string{q.Name, q.Path} {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	matches := []Match{}
+	for i := range entries {
+		e := &entries[i]
+		m := Match{EntityRef: entityRef(&e.Node), Slug: e.Slug, Repo: e.Repo}
+		switch e.Label {
+		case "Domain":
+			m.Domain = m.Name
+		case "Subdomain":
+			m.Domain, m.Subdomain = idx.NodeName(idx.PartOfDomain[e.Node.ID]), m.Name
+		default:
+			m.Domain, m.Subdomain = idx.NodeName(idx.BelongsToDomain[e.Node.ID]), idx.NodeName(idx.BelongsToSubdomain[e.Node.ID])
+		}
+		if q.matches(m) {
+			matches = append(matches, m)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return refLess(matches[i].EntityRef, matches[j].EntityRef) })
+	return matches, nil
+}
+
+func (q Query) matches(m Match) bool {
+	switch {
+	case q.ID != "" && m.ID != q.ID:
+		return false
+	case q.Type != "" && !strings.EqualFold(m.Type, q.Type):
+		return false
+	case q.Domain != "" && !strings.EqualFold(m.Domain, q.Domain):
+		return false
+	case q.Name != "" && !globMatch(q.Name, m.Name):
+		return false
+	case q.Path != "" && !globMatch(q.Path, m.Path) && !strings.HasPrefix(m.Path, strings.TrimSuffix(q.Path, "/")+"/"):
+		return false
+	}
+	return true
+}
+
+func globMatch(pattern, s string) bool {
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
@@ -0,0 +1,126 @@
+package graph2md
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Redirect maps the URL of a page's former location to its current URL.
+type Redirect struct {
+	OldPath string // former page path, as returned by PagePath
+	From    string
+	To      string
+}
+
+// PageHistory returns the former page paths of each entry's node, keyed by
+// node ID, from the previous run's manifest. A page moves when its slug or
+// the layout changes. Paths accumulate across runs, so a page moved twice
+// keeps both old URLs. Former paths that now belong to another page are
+// dropped.
+func (r *Renderer) PageHistory(prev *Manifest, entries []Entry) map[string][]string {
+	history := make(map[string][]string)
+	if prev == nil {
+		return history
+	}
+	current := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		current[r.PagePath(e.Slug)] = true
+	}
+	byID := make(map[string]ManifestPage, len(prev.Pages))
+	for _, p := range prev.Pages {
+		if p.NodeID != "" {
+			byID[p.NodeID] = p
+		}
+	}
+	for _, e := range entries {
+		p, ok := byID[e.Node.ID]
+		if !ok {
+			continue
+		}
+		last := strings.TrimSuffix(filepath.ToSlash(p.OutputPath), ".md")
+		if last == "" {
+			last = p.Slug
+		}
+		cur := r.PagePath(e.Slug)
+		var old []string
+		for _, s := range append(p.Aliases, last) {
+			if s != cur && !current[s] && !slices.Contains(old, s) {
+				old = append(old, s)
+			}
+		}
+		if len(old) > 0 {
+			sort.Strings(old)
+			history[e.Node.ID] = old
+		}
+	}
+	return history
+}
+
+// Redirects returns a redirect from every former page URL to the current
+// page URL, sorted by source URL. With LinkMarkdown the URLs are relative
+// .md paths, which redirect stubs, _redirects files and aliases can't use.
+func (r *Renderer) Redirects(entries []Entry) []Redirect {
+	var redirects []Redirect
+	for _, e := range entries {
+		for _, old := range r.Aliases[e.Node.ID] {
+			redirects = append(redirects, Redirect{OldPath: old, From: r.pathURL(old, ""), To: r.PageURL(e.Slug)})
+		}
+	}
+	sort.Slice(redirects, func(i, j int) bool { return redirects[i].From < redirects[j].From })
+	return redirects
+}
+
+// StubPath returns the path, relative to the site root, where an HTML
+// redirect stub must live to be served at the redirect's source URL.
+func (r *Renderer) StubPath(rd Redirect) string {
+	if r.LinkStyle == LinkPretty {
+		return rd.OldPath + "/index.html"
+	}
+	return rd.OldPath + ".html"
+}
+
+// RedirectStub returns a standalone HTML page that forwards to url.
+func RedirectStub(url string) string {
+	u := html.EscapeString(url)
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Redirecting…</title>
+<link rel="canonical" href="%s">
+<meta name="robots" content="noindex">
+<meta http-equiv="refresh" content="0; url=%s">
+</head>
+<body><p>This page has moved to <a href="%s">%s</a>.</p></body>
+</html>
+`, u, u, u, u)
+}
+
+// NetlifyRedirects returns a Netlify _redirects file with a permanent
+// redirect per entry.
+func NetlifyRedirects(redirects []Redirect) string {
+	var sb strings.Builder
+	for _, rd := range redirects {
+		sb.WriteString(fmt.Sprintf("%s %s 301\n", rd.From, rd.To))
+	}
+	return sb.String()
+}
+
+// writeAliases lists the page's former URLs as Hugo-style aliases.
+func (c *renderContext) writeAliases(sb *strings.Builder) {
+	if !c.AliasFrontmatter {
+		return
+	}
+	old := c.Aliases[c.node.ID]
+	if len(old) == 0 {
+		return
+	}
+	sb.WriteString("aliases:\n")
+	for _, p := range old {
+		sb.WriteString(fmt.Sprintf("  - %q\n", c.pathURL(p, "")))
+	}
+}
@@ -0,0 +1,89 @@
+package graph2md
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPageHistory(t *testing.T) {
+	entries := []Entry{
+		{Node: testNode("fn1", "Function", "name", "login"), Label: "Function", Slug: "fn-auth-ts-login"},
+		{Node: testNode("fn2", "Function", "name", "save"), Label: "Function", Slug: "fn-store-ts-save"},
+		{Node: testNode("fn3", "Function", "name", "load"), Label: "Function", Slug: "fn-load"},
+	}
+	r := &Renderer{Paths: map[string]string{"fn-auth-ts-login": "functions/fn-auth-ts-login"}}
+	prev := &Manifest{Pages: []ManifestPage{
+		// Moved from the flat layout, after an earlier rename.
+		{NodeID: "fn1", Slug: "fn-auth-ts-login", OutputPath: "fn-auth-ts-login.md", Aliases: []string{"fn-login"}},
+		// Its old path now belongs to fn3.
+		{NodeID: "fn2", Slug: "fn-load", OutputPath: "fn-load.md"},
+		// Unmoved.
+		{NodeID: "fn3", Slug: "fn-load", OutputPath: "fn-load.md"},
+		{NodeID: "gone", Slug: "fn-gone", OutputPath: "fn-gone.md"},
+	}}
+
+	got := r.PageHistory(prev, entries)
+	want := map[string][]string{"fn1": {"fn-auth-ts-login", "fn-login"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PageHistory = %v, want %v", got, want)
+	}
+	if got := r.PageHistory(nil, entries); len(got) != 0 {
+		t.Errorf("PageHistory(no manifest) = %v, want none", got)
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	entries := []Entry{{Node: testNode("fn1", "Function", "name", "login"), Label: "Function", Slug: "fn-login"}}
+	for _, tt := range []struct {
+		style LinkStyle
+		want  Redirect
+		stub  string
+	}{
+		{LinkHTML, Redirect{OldPath: "old/fn-login", From: "/docs/old/fn-login.html", To: "/docs/functions/fn-login.html"}, "old/fn-login.html"},
+		{LinkPretty, Redirect{OldPath: "old/fn-login", From: "/docs/old/fn-login/", To: "/docs/functions/fn-login/"}, "old/fn-login/index.html"},
+	} {
+		t.Run(string(tt.style), func(t *testing.T) {
+			r := &Renderer{
+				Paths:     map[string]string{"fn-login": "functions/fn-login"},
+				BasePath:  "/docs/",
+				LinkStyle: tt.style,
+				Aliases:   map[string][]string{"fn1": {"old/fn-login"}},
+			}
+			got := r.Redirects(entries)
+			if len(got) != 1 || got[0] != tt.want {
+				t.Fatalf("Redirects = %+v, want %+v", got, tt.want)
+			}
+			if stub := r.StubPath(got[0]); stub != tt.stub {
+				t.Errorf("StubPath = %q, want %q", stub, tt.stub)
+			}
+			if netlify := NetlifyRedirects(got); netlify != tt.want.From+" "+tt.want.To+" 301\n" {
+				t.Errorf("NetlifyRedirects = %q", netlify)
+			}
+		})
+	}
+}
+
+func TestRedirectStubEscapesURL(t *testing.T) {
+	stub := RedirectStub(`/a"b<c>.html`)
+	if strings.Contains(stub, `a"b`) || !strings.Contains(stub, `url=/a&#34;b&lt;c&gt;.html`) {
+		t.Errorf("RedirectStub didn't escape the URL:\n%s", stub)
+	}
+}
+
+func TestWriteAliases(t *testing.T) {
+	node := testNode("fn1", "Function", "name", "login")
+	r := &Renderer{LinkStyle: LinkPretty, Aliases: map[string][]string{"fn1": {"fn-login", "old/fn-login"}}}
+	c := &renderContext{Renderer: r, node: &node}
+
+	var sb strings.Builder
+	c.writeAliases(&sb)
+	if sb.Len() != 0 {
+		t.Errorf("aliases written without AliasFrontmatter:\n%s", sb.String())
+	}
+	r.AliasFrontmatter = true
+	c.writeAliases(&sb)
+	if want := "aliases:\n  - \"/fn-login/\"\n  - \"/old/fn-login/\"\n"; sb.String() != want {
+		t.Errorf("writeAliases =\n%s\nwant\n%s", sb.String(), want)
+	}
+}
@@ -0,0 +1,313 @@
+package graph2md
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Repo identifies the repository a graph was generated from, for sites
+// merged from several repositories.
+type Repo struct {
+	Name   string
+	URL    string
+	Branch string // branch for source links; empty uses Renderer.Branch
+	Commit string // pinned commit for source links, if known
+}
+
+// Renderer renders entity pages from an Index and a slug lookup.
+type Renderer struct {
+	*Index
+	Slugs    map[string]string // node ID -> slug, from AssignSlugs
+	Paths    map[string]string // slug -> page path, from LayoutPaths; nil is flat
+	RepoName string
+	RepoURL  string
+	BasePath string // URL path prefix for internal links, e.g. "/docs"
+
+	// LinkStyle selects the internal link URL form; the zero value is LinkHTML.
+	LinkStyle LinkStyle
+	// MarkdownLinks writes [label](url) links instead of HTML <a> tags.
+	MarkdownLinks bool
+
+	// SourceHost forms links to source files; the zero value is GitHub.
+	SourceHost SourceHost
+	Branch     string // branch for source links; defaults to "main"
+	Commit     string // pinned commit for source links, if known
+
+	// GeneratedAt is when the graph was generated, written to every page.
+	GeneratedAt string
+
+	// SourceRoot is a local checkout to embed code snippets from; snippets
+	// are skipped when it is empty. SnippetLines caps their length
+	// (DefaultSnippetLines when zero).
+	SourceRoot   string
+	SnippetLines int
+
+	// Aliases holds each node's former page paths, from PageHistory. They are
+	// recorded in the manifest, and written as an aliases frontmatter list
+	// when AliasFrontmatter is set.
+	Aliases          map[string][]string
+	AliasFrontmatter bool
+
+	srcMu    sync.Mutex
+	srcCache map[string]*sourceFile // file path -> contents, from SourceRoot
+	srcOrder []string               // cached paths, oldest first
+	srcBytes int                    // size of the cached files
+
+	// Diff, if set, adds a Changes section to the pages of entities that
+	// changed since an earlier snapshot, from DiffGraphs.
+	Diff        *GraphDiff
+	changesOnce sync.Once
+	changes     map[string][]entityChange
+
+	// Repos holds the repositories of a site merged from several, keyed by
+	// name, and NodeRepos each node's repository name. Pages of nodes
+	// without a repository use RepoName, RepoURL, Branch and Commit.
+	Repos     map[string]Repo
+	NodeRepos map[string]string
+
+	// Enrichments holds sidecar content keyed by node ID or slug, from
+	// LoadEnrichments. It may be nil.
+	Enrichments map[string]*Enrichment
+}
+
+// Render returns the markdown page for node. The node must have been
+// assigned a slug by AssignSlugs.
+func (r *Renderer) Render(node *Node) (string, error) {
+	slug, ok := r.Slugs[node.ID]
+	if !ok {
+		return "", fmt.Errorf("node %s has no page", node.ID)
+	}
+	return r.newContext(node, node.PrimaryLabel(), slug).generateMarkdown(), nil
+}
+
+// RenderEntry returns the markdown page for an entry from AssignSlugs.
+func (r *Renderer) RenderEntry(e Entry) string {
+	return r.newContext(&e.Node, e.Label, e.Slug).generateMarkdown()
+}
+
+type renderContext struct {
+	*Renderer
+	node        *Node
+	label, slug string
+	enrichment  *Enrichment
+
+	// The repository of the node being rendered. These shadow the
+	// Renderer's fields of the same names.
+	RepoName, RepoURL string
+	Branch, Commit    string
+}
+
+// siteContext returns a context for pages that belong to the whole site
+// rather than a node.
+func (r *Renderer) siteContext() *renderContext {
+	return &renderContext{
+		Renderer: r,
+		RepoName: r.RepoName,
+		RepoURL:  r.RepoURL,
+		Branch:   r.Branch,
+		Commit:   r.Commit,
+	}
+}
+
+func (r *Renderer) newContext(node *Node, label, slug string) *renderContext {
+	c := r.siteContext()
+	c.node = node
+	c.label = label
+	c.slug = slug
+	c.enrichment = r.enrichmentFor(node.ID, slug)
+	if repo, ok := r.Repos[r.NodeRepos[node.ID]]; ok {
+		c.RepoName, c.RepoURL, c.Commit = repo.Name, repo.URL, repo.Commit
+		if repo.Branch != "" {
+			c.Branch = repo.Branch
+		}
+	}
+	return c
+}
+
+// internalLink returns a link to the entity page for nodeID, or plain-text
+// label if no slug is found. Links to another repository's entities name
+// that repository.
+func (c *renderContext) internalLink(nodeID, label string) string {
+	if repo := c.NodeRepos[nodeID]; repo != "" && repo != c.nodeRepo() {
+		label += " (" + repo + ")"
+	}
+	slug, ok := c.Slugs[nodeID]
+	if !ok {
+		return c.text(label)
+	}
+	return c.anchor(c.pathURL(c.PagePath(slug), c.PagePath(c.slug)), label)
+}
+
+// domainLink links to a domain or subdomain node by its name.
+func (c *renderContext) domainLink(nodeID string) string {
+	return c.internalLink(nodeID, c.NodeName(nodeID))
+}
+
+// nodeRepo returns the repository of the node being rendered, if any.
+func (c *renderContext) nodeRepo() string {
+	if c.node == nil {
+		return ""
+	}
+	return c.NodeRepos[c.node.ID]
+}
+
+func (c *renderContext) generateMarkdown() string {
+	var sb strings.Builder
+
+	sb.WriteString("---\n")
+
+	var fm strings.Builder
+	switch c.label {
+	case "File":
+		c.writeFileFrontmatter(&fm)
+	case "Function":
+		c.writeFunctionFrontmatter(&fm)
+	case "Class":
+		c.writeClassFrontmatter(&fm)
+	case "Type":
+		c.writeTypeFrontmatter(&fm)
+	case "Domain":
+		c.writeDomainFrontmatter(&fm)
+	case "Subdomain":
+		c.writeSubdomainFrontmatter(&fm)
+	case "Directory":
+		c.writeDirectoryFrontmatter(&fm)
+	}
+
+	c.writeProvenance(&fm)
+	c.writeAliases(&fm)
+
+	// Write graph_data, mermaid_diagram, arch_map frontmatter fields
+	c.writeGraphData(&fm)
+	c.writeMermaidDiagram(&fm)
+	c.writeArchMap(&fm)
+
+	// Sidecar enrichments override generated fields
+	sb.WriteString(c.applyEnrichment(fm.String()))
+
+	sb.WriteString("---\n\n")
+
+	switch c.label {
+	case "File":
+		c.writeFileBody(&sb)
+	case "Function":
+		c.writeFunctionBody(&sb)
+	case "Class":
+		c.writeClassBody(&sb)
+	case "Type":
+		c.writeTypeBody(&sb)
+	case "Domain":
+		c.writeDomainBody(&sb)
+	case "Subdomain":
+		c.writeSubdomainBody(&sb)
+	case "Directory":
+		c.writeDirectoryBody(&sb)
+	}
+
+	c.writeChangesSection(&sb)
+
+	// FAQ section at the end of body
+	c.writeFAQSection(&sb)
+
+	return sb.String()
+}
+
+// --- Tag generation ---
+
+func (c *renderContext) writeTags(sb *strings.Builder) {
+	var tags []string
+
+	for _, label := range c.node.Labels {
+		tags = append(tags, label)
+	}
+
+	if lang := getStr(c.node.Properties, "language"); lang != "" {
+		tags = append(tags, lang)
+	}
+
+	ibCount := len(c.ImportedBy[c.node.ID])
+	impCount := len(c.Imports[c.node.ID])
+	cbCount := len(c.CalledBy[c.node.ID])
+
+	if ibCount >= 5 || cbCount >= 5 {
+		tags = append(tags, "High-Dependency")
+	}
+	if impCount >= 5 {
+		tags = append(tags, "Many-Imports")
+	}
+
+	funcCount := len(c.DefinesFunc[c.node.ID])
+	classCount := len(c.DeclaresClass[c.node.ID])
+	if funcCount >= 10 || classCount >= 5 {
+		tags = append(tags, "Complex")
+	}
+
+	if ibCount == 0 && impCount == 0 && cbCount == 0 && c.label == "File" {
+		tags = append(tags, "Isolated")
+	}
+
+	if c.enrichment != nil {
+		for _, t := range c.enrichment.Tags {
+			if !slices.Contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+	}
+
+	if len(tags) > 0 {
+		sb.WriteString("tags:\n")
+		for _, t := range tags {
+			sb.WriteString(fmt.Sprintf("  - %q\n", t))
+		}
+	}
+}
+
+// --- Helpers ---
+
+func (c *renderContext) resolveName(nodeID string) string {
+	n := c.Nodes[nodeID]
+	if n == nil {
+		return nodeID
+	}
+	name := getStr(n.Properties, "name")
+	if name == "" {
+		return nodeID
+	}
+	return name
+}
+
+func (c *renderContext) resolveNames(nodeIDs []string) []string {
+	result := make([]string, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		result = append(result, c.resolveName(id))
+	}
+	return result
+}
+
+func (c *renderContext) resolveNameWithPath(nodeID string) string {
+	n := c.Nodes[nodeID]
+	if n == nil {
+		return nodeID
+	}
+	path := getStr(n.Properties, "path")
+	if path == "" {
+		path = getStr(n.Properties, "filePath")
+	}
+	name := getStr(n.Properties, "name")
+	if path != "" {
+		return path
+	} else if name != "" {
+		return name
+	}
+	return nodeID
+}
+
+func (c *renderContext) resolveNamesWithPaths(nodeIDs []string) []string {
+	result := make([]string, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		result = append(result, c.resolveNameWithPath(id))
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+package graph2md
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JobState classifies the status of the analysis job behind an APIResponse.
+type JobState string
+
+const (
+	JobCompleted  JobState = "completed"
+	JobPending    JobState = "pending"
+	JobProcessing JobState = "processing"
+	JobFailed     JobState = "failed"
+	JobUnknown    JobState = "unknown" // a status graph2md doesn't recognize
+)
+
+// jobStates maps the status values the API and its proxies use to states.
+var jobStates = map[string]JobState{
+	"completed":   JobCompleted,
+	"complete":    JobCompleted,
+	"succeeded":   JobCompleted,
+	"success":     JobCompleted,
+	"done":        JobCompleted,
+	"pending":     JobPending,
+	"queued":      JobPending,
+	"submitted":   JobPending,
+	"processing":  JobProcessing,
+	"running":     JobProcessing,
+	"in_progress": JobProcessing,
+	"failed":      JobFailed,
+	"failure":     JobFailed,
+	"error":       JobFailed,
+	"cancelled":   JobFailed,
+	"canceled":    JobFailed,
+}
+
+// State returns the job state given by the response's status. A response
+// without a status, such as a bare graph wrapped by LoadResponse, is
+// complete unless it carries an error payload.
+func (r *APIResponse) State() JobState {
+	if r.Status == "" {
+		if r.hasError() {
+			return JobFailed
+		}
+		return JobCompleted
+	}
+	if s, ok := jobStates[strings.ToLower(r.Status)]; ok {
+		return s
+	}
+	return JobUnknown
+}
+
+func (r *APIResponse) hasError() bool {
+	return len(r.Error) > 0 && !bytes.Equal(bytes.TrimSpace(r.Error), []byte("null"))
+}
+
+// ResponseError reports an APIResponse that carries an error payload, has a
+// status other than completed, or has no result.
+type ResponseError struct {
+	State    JobState
+	Status   string
+	JobID    string
+	Payload  json.RawMessage // the response's error field, if any
+	NoResult bool
+}
+
+// Err returns a *ResponseError if the response did not complete
+// successfully, or nil.
+func (r *APIResponse) Err() error {
+	state := r.State()
+	if state == JobCompleted && !r.hasError() && r.Result != nil {
+		return nil
+	}
+	e := &ResponseError{State: state, Status: r.Status, JobID: r.JobID, NoResult: r.Result == nil}
+	if r.hasError() {
+		e.Payload = r.Error
+	}
+	return e
+}
+
+func (e *ResponseError) Error() string {
+	job := "API job"
+	if e.JobID != "" {
+		job += " " + e.JobID
+	}
+	var msg string
+	switch e.State {
+	case JobPending:
+		msg = job + " is pending"
+	case JobProcessing:
+		msg = job + " is still processing"
+	case JobFailed:
+		msg = job + " failed"
+	case JobUnknown:
+		msg = fmt.Sprintf("%s has unrecognized status %q", job, e.Status)
+	case JobCompleted:
+		if e.NoResult {
+			msg = job + " completed without a result"
+		} else {
+			msg = job + " completed with an error"
+		}
+	}
+	if m := e.Message(); m != "" {
+		msg += ": " + m
+	}
+	return msg
+}
+
+// Message returns the decoded error payload: a string payload as is, the
+// message (and code) of an object payload, or else the payload's JSON.
+func (e *ResponseError) Message() string {
+	if len(e.Payload) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(e.Payload, &s) == nil {
+		return s
+	}
+	var obj map[string]interface{}
+	if json.Unmarshal(e.Payload, &obj) == nil {
+		msg := getStr(obj, "message")
+		if msg == "" {
+			msg = getStr(obj, "error")
+		}
+		code := obj["code"]
+		switch {
+		case msg != "" && code != nil:
+			return fmt.Sprintf("%s (code %v)", msg, code)
+		case msg != "":
+			return msg
+		}
+	}
+	var buf bytes.Buffer
+	if json.Compact(&buf, e.Payload) == nil {
+		return buf.String()
+	}
+	return string(e.Payload)
+}
@@ -0,0 +1,257 @@
+package graph2md
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
+
+// toSlug converts a string to a URL-safe slug.
+func toSlug(s string) string {
+	s = strings.ToLower(s)
+	s = nonAlnum.ReplaceAllString(s, "-")
+	s = strings.Trim(s, "-")
+	return s
+}
+
+// GenerateLabels lists the node types that get their own page.
+var GenerateLabels = map[string]bool{
+	"File": true, "Function": true, "Class": true, "Type": true,
+	"Domain": true, "Subdomain": true, "Directory": true,
+}
+
+// Entry is a node that gets its own page, with its assigned slug.
+type Entry struct {
+	Node  Node
+	Label string
+	Slug  string
+	Repo  string // repository name, from AssignRepoSlugs
+}
+
+// AssignSlugs generates a unique slug for every node that gets a page and
+// returns the entries in input order along with a node ID -> slug lookup.
+//
+// Slugs don't depend on the order of the input. Entities whose slugs
+// collide (e.g. two login functions in different service.ts files) are
+// told apart by adding parent directories of their file until the slugs
+// differ; any that still collide are numbered -2, -3, ... in order of
+// their full path and node ID.
+func AssignSlugs(nodes []Node) ([]Entry, map[string]string) {
+	return AssignRepoSlugs(nodes, nil)
+}
+
+// AssignRepoSlugs is AssignSlugs for a site merged from several
+// repositories. nodeRepos maps node IDs to repository names; each node's
+// slug is prefixed with its repository's, so same-named entities in
+// different repositories don't collide.
+func AssignRepoSlugs(nodes []Node, nodeRepos map[string]string) ([]Entry, map[string]string) {
+	var entries []Entry
+	groups := make(map[string][]int) // base slug -> entry indices
+
+	for _, node := range nodes {
+		if len(node.Labels) == 0 {
+			continue
+		}
+		primaryLabel := node.Labels[0]
+		if !GenerateLabels[primaryLabel] {
+			continue
+		}
+
+		slug := generateSlug(node, primaryLabel)
+		if slug == "" {
+			continue
+		}
+		repo := nodeRepos[node.ID]
+		if repo != "" {
+			slug = toSlug(repo) + "-" + slug
+		}
+
+		groups[slug] = append(groups[slug], len(entries))
+		entries = append(entries, Entry{Node: node, Label: primaryLabel, Slug: slug, Repo: repo})
+	}
+
+	// Disambiguate colliding slugs with more of their file path.
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		maxDepth := 0
+		for _, i := range group {
+			maxDepth = max(maxDepth, len(slugDirs(entries[i].Node)))
+		}
+		distinct := func(depth int) int {
+			seen := make(map[string]bool)
+			for _, i := range group {
+				seen[qualifiedSlug(entries[i], depth)] = true
+			}
+			return len(seen)
+		}
+		depth, best := 0, distinct(maxDepth)
+		for distinct(depth) < best {
+			depth++
+		}
+		for _, i := range group {
+			entries[i].Slug = qualifiedSlug(entries[i], depth)
+		}
+	}
+
+	// Number whatever still collides, in canonical order.
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ea, eb := entries[order[a]], entries[order[b]]
+		if ea.Slug != eb.Slug {
+			return ea.Slug < eb.Slug
+		}
+		if ka, kb := canonicalPath(ea.Node), canonicalPath(eb.Node); ka != kb {
+			return ka < kb
+		}
+		return ea.Node.ID < eb.Node.ID
+	})
+	taken := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		taken[e.Slug] = true
+	}
+	used := make(map[string]bool, len(entries))
+	for _, i := range order {
+		slug := entries[i].Slug
+		if used[slug] {
+			for n := 2; ; n++ {
+				candidate := fmt.Sprintf("%s-%d", slug, n)
+				if !taken[candidate] && !used[candidate] {
+					slug = candidate
+					break
+				}
+			}
+		}
+		used[slug] = true
+		entries[i].Slug = slug
+	}
+
+	slugLookup := make(map[string]string, len(entries))
+	for _, e := range entries {
+		slugLookup[e.Node.ID] = e.Slug
+	}
+	return entries, slugLookup
+}
+
+// canonicalPath is the path an entity is ordered by when breaking ties.
+func canonicalPath(node Node) string {
+	if p := getStr(node.Properties, "filePath"); p != "" {
+		return p
+	}
+	return getStr(node.Properties, "path")
+}
+
+// slugDirs returns the directories of the file a function, class or type
+// is defined in, which can be added to its slug to tell it apart.
+func slugDirs(node Node) []string {
+	filePath := getStr(node.Properties, "filePath")
+	dir := filepath.Dir(filePath)
+	if filePath == "" || dir == "." || dir == "/" {
+		return nil
+	}
+	return strings.Split(strings.Trim(dir, "/"), "/")
+}
+
+// qualifiedSlug returns the entry's slug with up to depth parent
+// directories of its file added, e.g. fn-auth-service-ts-login at depth 1.
+func qualifiedSlug(e Entry, depth int) string {
+	slug := dirSlug(e, depth)
+	if e.Repo != "" {
+		slug = toSlug(e.Repo) + "-" + slug
+	}
+	return slug
+}
+
+// dirSlug is qualifiedSlug without the repository prefix.
+func dirSlug(e Entry, depth int) string {
+	dirs := slugDirs(e.Node)
+	if depth == 0 || len(dirs) == 0 {
+		return generateSlug(e.Node, e.Label)
+	}
+	dirs = dirs[max(0, len(dirs)-depth):]
+	var prefix string
+	switch e.Label {
+	case "Function":
+		prefix = "fn-"
+	case "Class":
+		prefix = "class-"
+	case "Type":
+		prefix = "type-"
+	default:
+		return generateSlug(e.Node, e.Label)
+	}
+	filePath := getStr(e.Node.Properties, "filePath")
+	name := getStr(e.Node.Properties, "name")
+	return toSlug(prefix + strings.Join(dirs, "-") + "-" + filepath.Base(filePath) + "-" + name)
+}
+
+func generateSlug(node Node, label string) string {
+	props := node.Properties
+
+	switch label {
+	case "File":
+		path := getStr(props, "path")
+		if path == "" {
+			return ""
+		}
+		return toSlug("file-" + path)
+	case "Function":
+		name := getStr(props, "name")
+		filePath := getStr(props, "filePath")
+		if name == "" {
+			return ""
+		}
+		if filePath != "" {
+			return toSlug("fn-" + filepath.Base(filePath) + "-" + name)
+		}
+		return toSlug("fn-" + name)
+	case "Class":
+		name := getStr(props, "name")
+		filePath := getStr(props, "filePath")
+		if name == "" {
+			return ""
+		}
+		if filePath != "" {
+			return toSlug("class-" + filepath.Base(filePath) + "-" + name)
+		}
+		return toSlug("class-" + name)
+	case "Type":
+		name := getStr(props, "name")
+		filePath := getStr(props, "filePath")
+		if name == "" {
+			return ""
+		}
+		if filePath != "" {
+			return toSlug("type-" + filepath.Base(filePath) + "-" + name)
+		}
+		return toSlug("type-" + name)
+	case "Domain":
+		name := getStr(props, "name")
+		if name == "" {
+			return ""
+		}
+		return toSlug("domain-" + name)
+	case "Subdomain":
+		name := getStr(props, "name")
+		if name == "" {
+			return ""
+		}
+		return toSlug("subdomain-" + name)
+	case "Directory":
+		path := getStr(props, "path")
+		if path == "" || strings.Contains(path, "/app/repo-root/") {
+			return ""
+		}
+		return toSlug("dir-" + path)
+	default:
+		return ""
+	}
+}
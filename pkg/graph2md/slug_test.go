@@ -0,0 +1,65 @@
+package graph2md
+
+import (
+	"maps"
+	"math/rand"
+	"testing"
+)
+
+func TestAssignRepoSlugsOrderIndependent(t *testing.T) {
+	api := []Node{
+		testNode("f1", "File", "path", "src/auth/service.ts"),
+		testNode("fn1", "Function", "name", "login", "filePath", "src/auth/service.ts"),
+		testNode("fn2", "Function", "name", "login", "filePath", "src/billing/service.ts"),
+		testNode("fn3", "Function", "name", "login", "filePath", "lib/auth/service.ts"),
+		// Same file and name: only numbering tells these apart.
+		testNode("fn4", "Function", "name", "save", "filePath", "src/store.ts"),
+		testNode("fn5", "Function", "name", "save", "filePath", "src/store.ts"),
+		testNode("c1", "Class", "name", "Auth", "filePath", "src/auth/service.ts"),
+		testNode("dom1", "Domain", "name", "Auth"),
+		testNode("x1", "Module", "name", "ignored"),
+	}
+	web := []Node{
+		testNode("w-f1", "File", "path", "src/auth/service.ts"),
+		testNode("w-fn1", "Function", "name", "login", "filePath", "src/auth/service.ts"),
+	}
+	nodeRepos := map[string]string{"w-f1": "web", "w-fn1": "web"}
+
+	want := map[string]string{
+		"f1":    "file-src-auth-service-ts",
+		"fn1":   "fn-src-auth-service-ts-login",
+		"fn2":   "fn-src-billing-service-ts-login",
+		"fn3":   "fn-lib-auth-service-ts-login",
+		"fn4":   "fn-store-ts-save",
+		"fn5":   "fn-store-ts-save-2",
+		"c1":    "class-service-ts-auth",
+		"dom1":  "domain-auth",
+		"w-f1":  "web-file-src-auth-service-ts",
+		"w-fn1": "web-fn-service-ts-login",
+	}
+
+	inputs := [][]Node{api, web}
+	rng := rand.New(rand.NewSource(1))
+	for i := range 50 {
+		// Alternate the order the inputs are merged in, and shuffle the
+		// nodes within each.
+		m := NewMerger(MergeFirst)
+		for j := range inputs {
+			in := append([]Node{}, inputs[(i+j)%len(inputs)]...)
+			rng.Shuffle(len(in), func(a, b int) { in[a], in[b] = in[b], in[a] })
+			m.Add("input", Graph{Nodes: in})
+		}
+		entries, slugs := AssignRepoSlugs(m.Graph().Nodes, nodeRepos)
+		if !maps.Equal(slugs, want) {
+			t.Fatalf("order %d: slugs = %v, want %v", i, slugs, want)
+		}
+		if len(entries) != len(want) {
+			t.Fatalf("order %d: %d entries, want %d", i, len(entries), len(want))
+		}
+		for _, e := range entries {
+			if e.Repo != nodeRepos[e.Node.ID] {
+				t.Errorf("order %d: %s has repo %q, want %q", i, e.Node.ID, e.Repo, nodeRepos[e.Node.ID])
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package graph2md
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultSnippetLines is the snippet length limit used when
+// Renderer.SnippetLines is zero.
+const DefaultSnippetLines = 200
+
+// fenceLanguages maps graph language names to code fence info strings
+// where the lowercase name isn't what highlighters expect.
+var fenceLanguages = map[string]string{
+	"c#":          "csharp",
+	"c++":         "cpp",
+	"f#":          "fsharp",
+	"objective-c": "objectivec",
+	"shell":       "bash",
+	"vue":         "html",
+}
+
+// extLanguages maps file extensions to fence info strings, for nodes with
+// no language property.
+var extLanguages = map[string]string{
+	".go": "go", ".py": "python", ".ts": "typescript", ".tsx": "tsx",
+	".js": "javascript", ".jsx": "jsx", ".java": "java", ".kt": "kotlin",
+	".rb": "ruby", ".rs": "rust", ".cs": "csharp", ".cpp": "cpp",
+	".c": "c", ".h": "c", ".php": "php", ".swift": "swift", ".scala": "scala",
+	".sh": "bash",
+}
+
+// fenceLanguage returns the code fence info string for a node.
+func fenceLanguage(lang, path string) string {
+	if lang == "" {
+		return extLanguages[strings.ToLower(filepath.Ext(path))]
+	}
+	lang = strings.ToLower(lang)
+	if l, ok := fenceLanguages[lang]; ok {
+		return l
+	}
+	return strings.ReplaceAll(lang, " ", "")
+}
+
+// sourceCacheBytes bounds the size of the source files kept in memory for
+// snippets. Files are dropped oldest first once it is exceeded.
+var sourceCacheBytes = 64 << 20
+
+// sourceFile is a source file read for snippets. It is read once, by the
+// first page that needs it; other pages wait for that read.
+type sourceFile struct {
+	once  sync.Once
+	lines []string
+	size  int // guarded by Renderer.srcMu
+}
+
+// sourceLines returns the lines of path under SourceRoot. Files are read
+// outside the cache lock, so concurrent renders read different files in
+// parallel, and recently read files are kept up to sourceCacheBytes. It
+// returns nil if the file can't be read or escapes SourceRoot.
+func (r *Renderer) sourceLines(path string) []string {
+	if !filepath.IsLocal(filepath.FromSlash(path)) {
+		return nil
+	}
+	r.srcMu.Lock()
+	f, ok := r.srcCache[path]
+	if !ok {
+		if r.srcCache == nil {
+			r.srcCache = make(map[string]*sourceFile)
+		}
+		f = &sourceFile{}
+		r.srcCache[path] = f
+		r.srcOrder = append(r.srcOrder, path)
+	}
+	r.srcMu.Unlock()
+
+	f.once.Do(func() {
+		data, err := os.ReadFile(filepath.Join(r.SourceRoot, filepath.FromSlash(path)))
+		if err == nil {
+			f.lines = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		}
+		r.cacheSource(path, f, len(data))
+	})
+	return f.lines
+}
+
+// cacheSource accounts for a file of size bytes just read into the cache,
+// dropping the oldest files while the cache is over its limit. The file
+// itself is kept even if it alone exceeds the limit, as pages of its
+// entities are likely to follow.
+func (r *Renderer) cacheSource(path string, f *sourceFile, size int) {
+	r.srcMu.Lock()
+	defer r.srcMu.Unlock()
+	if r.srcCache[path] != f {
+		return // dropped while it was being read
+	}
+	f.size = size
+	r.srcBytes += size
+	for r.srcBytes > sourceCacheBytes && len(r.srcOrder) > 1 {
+		oldest := r.srcOrder[0]
+		r.srcOrder = r.srcOrder[1:]
+		if oldest == path {
+			r.srcOrder = append(r.srcOrder, path)
+			continue
+		}
+		r.srcBytes -= r.srcCache[oldest].size
+		delete(r.srcCache, oldest)
+	}
+}
+
+// writeSnippet writes a fenced code block with lines start to end of path,
+// truncated to the snippet limit. It writes nothing when SourceRoot is
+// unset, the line range is unknown, or the file is missing or shorter than
+// expected.
+func (c *renderContext) writeSnippet(sb *strings.Builder, path string, start, end int) {
+	if c.SourceRoot == "" || path == "" || start <= 0 {
+		return
+	}
+	if end < start {
+		end = start
+	}
+	lines := c.sourceLines(path)
+	if start > len(lines) {
+		return
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	limit := c.SnippetLines
+	if limit <= 0 {
+		limit = DefaultSnippetLines
+	}
+	snippet := lines[start-1 : end]
+	omitted := 0
+	if len(snippet) > limit {
+		omitted = len(snippet) - limit
+		snippet = snippet[:limit]
+	}
+
+	// Use a fence longer than any backtick run in the code itself.
+	fence := "```"
+	for _, l := range snippet {
+		for strings.Contains(l, fence) {
+			fence += "`"
+		}
+	}
+
+	sb.WriteString(fence + fenceLanguage(getStr(c.node.Properties, "language"), path) + "\n")
+	for _, l := range snippet {
+		sb.WriteString(l + "\n")
+	}
+	sb.WriteString(fence + "\n\n")
+	if omitted > 0 {
+		sb.WriteString(fmt.Sprintf("*%d more lines not shown.*\n\n", omitted))
+	}
+}
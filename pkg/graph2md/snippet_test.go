@@ -0,0 +1,66 @@
+package graph2md
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSourceLinesConcurrent(t *testing.T) {
+	root := t.TempDir()
+	const files = 20
+	for i := range files {
+		content := strings.Repeat(fmt.Sprintf("line of file %d\n", i), 10)
+		if err := os.WriteFile(filepath.Join(root, fmt.Sprintf("f%d.go", i)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func(n int) { sourceCacheBytes = n }(sourceCacheBytes)
+	sourceCacheBytes = 500 // a few files
+
+	r := &Renderer{SourceRoot: root}
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range files * 3 {
+				n := (i + w) % files
+				lines := r.sourceLines(fmt.Sprintf("f%d.go", n))
+				if want := fmt.Sprintf("line of file %d", n); len(lines) != 11 || lines[0] != want {
+					t.Errorf("f%d.go: got %d lines starting %q", n, len(lines), lines[0])
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	r.srcMu.Lock()
+	defer r.srcMu.Unlock()
+	total := 0
+	for _, f := range r.srcCache {
+		total += f.size
+	}
+	if total != r.srcBytes {
+		t.Errorf("cache holds %d bytes, accounted %d", total, r.srcBytes)
+	}
+	if r.srcBytes > sourceCacheBytes+200 {
+		t.Errorf("cache holds %d bytes, limit %d", r.srcBytes, sourceCacheBytes)
+	}
+	if len(r.srcCache) != len(r.srcOrder) {
+		t.Errorf("%d cached files, %d in eviction order", len(r.srcCache), len(r.srcOrder))
+	}
+}
+
+func TestSourceLinesOutsideRoot(t *testing.T) {
+	r := &Renderer{SourceRoot: t.TempDir()}
+	for _, p := range []string{"../secret", "/etc/passwd", "missing.go"} {
+		if lines := r.sourceLines(p); lines != nil {
+			t.Errorf("sourceLines(%q) = %v, want nil", p, lines)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package graph2md
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SourceHost describes how to link to a file on a code host. Templates may
+// use these placeholders:
+//
+//	{repo}    repository URL
+//	{path}    file path within the repository
+//	{start}   first line of the entity
+//	{end}     last line of the entity (equal to {start} when unknown)
+//	{branch}  branch name
+//	{commit}  pinned commit (empty when unknown)
+//	{ref}     the commit when known, otherwise the branch
+//
+// When a node has no line range, the template is cut at the last '#', '&'
+// or '?' before {start}, so the same template serves files and functions.
+type SourceHost struct {
+	Name   string // shown as "View on <Name>"; empty shows "View source"
+	Branch string // URL template used when no commit is pinned
+	Commit string // URL template used when a commit is pinned
+}
+
+// SourceHosts are the built-in code host presets.
+var SourceHosts = map[string]SourceHost{
+	"github": {
+		Name:   "GitHub",
+		Branch: "{repo}/blob/{ref}/{path}#L{start}-L{end}",
+		Commit: "{repo}/blob/{ref}/{path}#L{start}-L{end}",
+	},
+	"gitlab": {
+		Name:   "GitLab",
+		Branch: "{repo}/-/blob/{ref}/{path}#L{start}-{end}",
+		Commit: "{repo}/-/blob/{ref}/{path}#L{start}-{end}",
+	},
+	"bitbucket": {
+		Name:   "Bitbucket",
+		Branch: "{repo}/src/{ref}/{path}#lines-{start}:{end}",
+		Commit: "{repo}/src/{ref}/{path}#lines-{start}:{end}",
+	},
+	"gitea": {
+		Name:   "Gitea",
+		Branch: "{repo}/src/branch/{branch}/{path}#L{start}-L{end}",
+		Commit: "{repo}/src/commit/{commit}/{path}#L{start}-L{end}",
+	},
+	"azure": {
+		Name:   "Azure DevOps",
+		Branch: "{repo}?path=/{path}&version=GB{branch}&line={start}&lineEnd={end}&lineStartColumn=1&lineEndColumn=1",
+		Commit: "{repo}?path=/{path}&version=GC{commit}&line={start}&lineEnd={end}&lineStartColumn=1&lineEndColumn=1",
+	},
+}
+
+// ParseSourceHost returns the preset named s, or a custom host using s as
+// the URL template for both branches and commits.
+func ParseSourceHost(s string) (SourceHost, error) {
+	if h, ok := SourceHosts[s]; ok {
+		return h, nil
+	}
+	if !strings.Contains(s, "{path}") {
+		names := make([]string, 0, len(SourceHosts))
+		for name := range SourceHosts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return SourceHost{}, fmt.Errorf("source URL template %q has no {path} placeholder and is not a preset (%s)", s, strings.Join(names, ", "))
+	}
+	return SourceHost{Branch: s, Commit: s}, nil
+}
+
+// SourceURL returns the code host link for path, covering lines start to
+// end when start is known. It returns "" when RepoURL is unset.
+func (r *Renderer) SourceURL(path string, start, end int) string {
+	return r.sourceURL(Repo{Name: r.RepoName, URL: r.RepoURL, Branch: r.Branch, Commit: r.Commit}, path, start, end)
+}
+
+// sourceURL is SourceURL for a file in repo.
+func (r *Renderer) sourceURL(repo Repo, path string, start, end int) string {
+	if repo.URL == "" || path == "" {
+		return ""
+	}
+	host := r.SourceHost
+	if host.Branch == "" {
+		host = SourceHosts["github"]
+	}
+	branch := repo.Branch
+	if branch == "" {
+		branch = "main"
+	}
+	tmpl, ref := host.Branch, branch
+	if repo.Commit != "" {
+		tmpl, ref = host.Commit, repo.Commit
+	}
+
+	if start <= 0 {
+		if i := strings.Index(tmpl, "{start}"); i >= 0 {
+			if j := strings.LastIndexAny(tmpl[:i], "#&?"); j >= 0 {
+				tmpl = tmpl[:j]
+			}
+		}
+	}
+	if end < start {
+		end = start
+	}
+
+	return strings.NewReplacer(
+		"{repo}", strings.TrimSuffix(repo.URL, "/"),
+		"{path}", path,
+		"{start}", strconv.Itoa(start),
+		"{end}", strconv.Itoa(end),
+		"{branch}", branch,
+		"{commit}", repo.Commit,
+		"{ref}", ref,
+	).Replace(tmpl)
+}
+
+// sourceLinkText is the label for links produced by SourceURL.
+func (r *Renderer) sourceLinkText() string {
+	name := r.SourceHost.Name
+	if r.SourceHost.Branch == "" {
+		name = SourceHosts["github"].Name
+	}
+	if name == "" {
+		return "View source"
+	}
+	return "View on " + name
+}
+
+// writeSourceSection writes the "## Source" section for the entity at path:
+// an embedded snippet when SourceRoot is set, and a link to the code host.
+func (c *renderContext) writeSourceSection(sb *strings.Builder, path string, start, end int) {
+	var section strings.Builder
+	c.writeSnippet(&section, path, start, end)
+	repo := Repo{Name: c.RepoName, URL: c.RepoURL, Branch: c.Branch, Commit: c.Commit}
+	if link := c.sourceURL(repo, path, start, end); link != "" {
+		section.WriteString(fmt.Sprintf("- %s\n\n", c.anchor(link, c.sourceLinkText())))
+	}
+	if section.Len() == 0 {
+		return
+	}
+	sb.WriteString("## Source\n\n")
+	sb.WriteString(section.String())
+}
@@ -0,0 +1,151 @@
+package graph2md
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Severity grades a Problem. Errors are defects that lose or misrender
+// content; warnings are graph shapes graph2md doesn't expect.
+type Severity string
+
+const (
+	// SeverityError fails validation.
+	SeverityError Severity = "error"
+	// SeverityWarning is reported but fails validation only in strict mode.
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is an issue Validate found in a graph.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Kind     string   `json:"kind"`
+	ID       string   `json:"id,omitempty"` // ID of the node or relationship at fault
+	Message  string   `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Kind, p.Message)
+}
+
+// Problem kinds.
+const (
+	// ProblemDanglingEdge is a relationship whose start or end node is not
+	// in the graph. Its page links render as raw node IDs.
+	ProblemDanglingEdge = "dangling-edge"
+	// ProblemNoLabels is a node without labels. It gets no page.
+	ProblemNoLabels = "no-labels"
+	// ProblemUnknownLabel is a node whose primary label graph2md doesn't
+	// generate pages for.
+	ProblemUnknownLabel = "unknown-label"
+	// ProblemMissingProperty is a node without a property its type needs,
+	// such as a File's path.
+	ProblemMissingProperty = "missing-property"
+	// ProblemEndpointType is a relationship between nodes of types it
+	// doesn't connect, such as a calls edge from a File.
+	ProblemEndpointType = "endpoint-type"
+	// ProblemStatsMismatch is a graph whose reported stats disagree with
+	// its contents.
+	ProblemStatsMismatch = "stats-mismatch"
+)
+
+// requiredProps are the properties each node type needs to be rendered.
+var requiredProps = map[string][]string{
+	"File":      {"path"},
+	"Directory": {"path"},
+	"Function":  {"name"},
+	"Class":     {"name"},
+	"Type":      {"name"},
+	"Domain":    {"name"},
+	"Subdomain": {"name"},
+}
+
+// endpointTypes are the node types each relationship type connects, as
+// start types and end types. Other relationship types aren't checked.
+var endpointTypes = map[string][2][]string{
+	"CHILD_DIRECTORY":  {{"Directory"}, {"Directory"}},
+	"CONTAINS_FILE":    {{"Directory"}, {"File"}},
+	"IMPORTS":          {{"File"}, {"File"}},
+	"DEFINES_FUNCTION": {{"File", "Class"}, {"Function"}}, // class -> method
+	"DECLARES_CLASS":   {{"File"}, {"Class"}},
+	"DEFINES":          {{"File"}, {"Type"}},
+	"calls":            {{"Function"}, {"Function"}},
+	"EXTENDS":          {{"Class", "Type"}, {"Class", "Type"}},
+	"belongsTo":        {{"File", "Function", "Class", "Type"}, {"Domain", "Subdomain"}},
+	"partOf":           {{"Subdomain"}, {"Domain"}},
+}
+
+// Validate checks g for dangling relationships, nodes without labels,
+// unknown node types or missing required properties, and relationships
+// between unexpected node types. Problems are returned in graph order,
+// nodes first.
+func Validate(g Graph) []Problem {
+	var problems []Problem
+	add := func(sev Severity, kind, id, format string, args ...interface{}) {
+		problems = append(problems, Problem{Severity: sev, Kind: kind, ID: id, Message: fmt.Sprintf(format, args...)})
+	}
+
+	nodes := make(map[string]*Node, len(g.Nodes))
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		nodes[n.ID] = n
+		label := n.PrimaryLabel()
+		switch {
+		case label == "":
+			add(SeverityError, ProblemNoLabels, n.ID, "node %s has no labels", n.ID)
+		case !GenerateLabels[label]:
+			add(SeverityWarning, ProblemUnknownLabel, n.ID, "node %s has unknown type %s", n.ID, label)
+		}
+		for _, prop := range requiredProps[label] {
+			if getStr(n.Properties, prop) == "" {
+				add(SeverityError, ProblemMissingProperty, n.ID, "%s node %s has no %s", label, n.ID, prop)
+			}
+		}
+	}
+
+	for _, rel := range g.Relationships {
+		want, checked := endpointTypes[rel.Type]
+		for i, end := range []struct{ side, id string }{{"start", rel.StartNode}, {"end", rel.EndNode}} {
+			n, ok := nodes[end.id]
+			if !ok {
+				add(SeverityError, ProblemDanglingEdge, rel.ID, "%s relationship %s: %s node %q not found", rel.Type, rel.ID, end.side, end.id)
+				continue
+			}
+			if label := n.PrimaryLabel(); checked && label != "" && !slices.Contains(want[i], label) {
+				add(SeverityWarning, ProblemEndpointType, rel.ID, "%s relationship %s: %s node %s is a %s, want %s", rel.Type, rel.ID, end.side, end.id, label, orList(want[i]))
+			}
+		}
+	}
+	return problems
+}
+
+// ValidateStats checks that the node and relationship counts reported in
+// r's stats, if any, match its graph.
+func ValidateStats(r *GraphResult) []Problem {
+	var problems []Problem
+	for _, c := range []struct {
+		what        string
+		stat, count int
+	}{
+		{"nodes", r.Stats.NodeCount, len(r.Graph.Nodes)},
+		{"relationships", r.Stats.RelationshipCount, len(r.Graph.Relationships)},
+	} {
+		if c.stat > 0 && c.stat != c.count {
+			problems = append(problems, Problem{
+				Severity: SeverityError,
+				Kind:     ProblemStatsMismatch,
+				Message:  fmt.Sprintf("stats report %d %s, graph has %d", c.stat, c.what, c.count),
+			})
+		}
+	}
+	return problems
+}
+
+// orList joins items as "a", "a or b", "a, b or c".
+func orList(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
@@ -0,0 +1,87 @@
+package graph2md
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Graph{
+		Nodes: []Node{
+			testNode("d1", "Directory", "path", "src"),
+			testNode("f1", "File", "path", "src/auth.ts"),
+			testNode("c1", "Class", "name", "Auth", "filePath", "src/auth.ts"),
+			testNode("fn1", "Function", "name", "login", "filePath", "src/auth.ts"),
+			testNode("fn2", "Function", "name", "check", "filePath", "src/auth.ts"),
+			testNode("dom1", "Domain", "name", "Auth"),
+			testNode("sub1", "Subdomain", "name", "Sessions"),
+		},
+		Relationships: []Relationship{
+			testRel("r1", "CONTAINS_FILE", "d1", "f1"),
+			testRel("r2", "DECLARES_CLASS", "f1", "c1"),
+			testRel("r3", "DEFINES_FUNCTION", "f1", "fn1"),
+			testRel("r4", "DEFINES_FUNCTION", "c1", "fn2"), // method
+			testRel("r5", "calls", "fn1", "fn2"),
+			testRel("r6", "belongsTo", "fn1", "dom1"),
+			testRel("r7", "partOf", "sub1", "dom1"),
+		},
+	}
+	if problems := Validate(valid); len(problems) != 0 {
+		t.Errorf("Validate(valid graph) = %v, want no problems", problems)
+	}
+
+	tests := []struct {
+		name     string
+		node     *Node
+		rel      *Relationship
+		kind     string
+		severity Severity
+	}{
+		{"dangling edge", nil, &Relationship{ID: "x", Type: "calls", StartNode: "fn1", EndNode: "missing"}, ProblemDanglingEdge, SeverityError},
+		{"no labels", &Node{ID: "x"}, nil, ProblemNoLabels, SeverityError},
+		{"unknown label", &Node{ID: "x", Labels: []string{"Module"}}, nil, ProblemUnknownLabel, SeverityWarning},
+		{"file without path", &Node{ID: "x", Labels: []string{"File"}}, nil, ProblemMissingProperty, SeverityError},
+		{"function without name", &Node{ID: "x", Labels: []string{"Function"}}, nil, ProblemMissingProperty, SeverityError},
+		{"call from a file", nil, &Relationship{ID: "x", Type: "calls", StartNode: "f1", EndNode: "fn1"}, ProblemEndpointType, SeverityWarning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := Graph{
+				Nodes:         append([]Node{}, valid.Nodes...),
+				Relationships: append([]Relationship{}, valid.Relationships...),
+			}
+			if tt.node != nil {
+				g.Nodes = append(g.Nodes, *tt.node)
+			}
+			if tt.rel != nil {
+				g.Relationships = append(g.Relationships, *tt.rel)
+			}
+			problems := Validate(g)
+			if len(problems) != 1 {
+				t.Fatalf("Validate = %v, want one %s problem", problems, tt.kind)
+			}
+			if p := problems[0]; p.Kind != tt.kind || p.Severity != tt.severity || p.ID != "x" {
+				t.Errorf("Validate = %+v, want %s %s for x", p, tt.severity, tt.kind)
+			}
+		})
+	}
+}
+
+func TestValidateStats(t *testing.T) {
+	r := &GraphResult{
+		Stats: GraphStats{NodeCount: 3, RelationshipCount: 1},
+		Graph: Graph{
+			Nodes:         []Node{testNode("f1", "File", "path", "a.ts"), testNode("f2", "File", "path", "b.ts")},
+			Relationships: []Relationship{testRel("r1", "IMPORTS", "f1", "f2")},
+		},
+	}
+	problems := ValidateStats(r)
+	if len(problems) != 1 || problems[0].Kind != ProblemStatsMismatch || !strings.Contains(problems[0].Message, "3 nodes") {
+		t.Errorf("ValidateStats = %v, want a node count mismatch", problems)
+	}
+
+	r.Stats = GraphStats{}
+	if problems := ValidateStats(r); len(problems) != 0 {
+		t.Errorf("ValidateStats without reported stats = %v, want none", problems)
+	}
+}
@@ -0,0 +1,146 @@
+package graph2md
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// WriteStats counts how pages changed relative to the previous run.
+type WriteStats struct {
+	Added     int
+	Changed   int
+	Restamped int // rewritten only to update their commit and generation time
+	Unchanged int
+	Removed   int
+}
+
+func (s WriteStats) String() string {
+	return fmt.Sprintf("%d added, %d changed, %d restamped, %d unchanged, %d removed", s.Added, s.Changed, s.Restamped, s.Unchanged, s.Removed)
+}
+
+// Writer writes pages into an output directory, leaving files whose content
+// hasn't changed untouched so their modification times survive and site
+// builders don't rebuild them. It is safe for concurrent use.
+type Writer struct {
+	Dir   string
+	Force bool // rewrite every page, even if unchanged
+
+	prev  map[string]string // output path -> content hash from the previous manifest
+	mu    sync.Mutex
+	stats WriteStats
+}
+
+// NewWriter returns a Writer for dir. prev is the manifest of the previous
+// run, if any; its hashes let unchanged pages be skipped without reading
+// them back from disk.
+func NewWriter(dir string, prev *Manifest) *Writer {
+	w := &Writer{Dir: dir, prev: make(map[string]string)}
+	if prev != nil {
+		for _, p := range prev.Pages {
+			w.prev[p.OutputPath] = p.Hash
+		}
+	}
+	return w
+}
+
+// provenanceKeys are the frontmatter lines written by writeProvenance that
+// change with every graph snapshot, not with the page.
+var provenanceKeys = []string{"commit: ", "generated_at: "}
+
+// ContentHash returns the hash recorded in the manifest for page content.
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// stableContent returns content without its provenance frontmatter lines.
+func stableContent(content string) string {
+	fm, ok := strings.CutPrefix(content, "---\n")
+	if !ok {
+		return content
+	}
+	end := strings.Index(fm, "\n---\n")
+	if end < 0 {
+		return content
+	}
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, line := range strings.SplitAfter(fm[:end+1], "\n") {
+		if !slices.ContainsFunc(provenanceKeys, func(k string) bool { return strings.HasPrefix(line, k) }) {
+			sb.WriteString(line)
+		}
+	}
+	sb.WriteString(fm[end+1:])
+	return sb.String()
+}
+
+// Write writes content to relPath under the output directory unless the
+// file already holds exactly that content. A file that differs only in its
+// provenance lines is rewritten, so every page records the snapshot it was
+// generated from, but counted as restamped rather than changed.
+func (w *Writer) Write(relPath, content string) error {
+	hash := ContentHash(content)
+	path := filepath.Join(w.Dir, relPath)
+
+	info, err := os.Stat(path)
+	exists := err == nil
+	restamped := false
+	if exists && !w.Force {
+		existing, same := w.unchanged(relPath, path, hash, content, info.Size())
+		if same {
+			w.count(func(s *WriteStats) { s.Unchanged++ })
+			return nil
+		}
+		restamped = existing != nil && stableContent(string(existing)) == stableContent(content)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+	switch {
+	case restamped:
+		w.count(func(s *WriteStats) { s.Restamped++ })
+	case exists:
+		w.count(func(s *WriteStats) { s.Changed++ })
+	default:
+		w.count(func(s *WriteStats) { s.Added++ })
+	}
+	return nil
+}
+
+// unchanged reports whether the file at path already holds content. A
+// matching hash from the previous manifest (and a matching size) is taken
+// as proof; otherwise the file is read and compared, and its content is
+// returned if it could be read.
+func (w *Writer) unchanged(relPath, path, hash, content string, size int64) ([]byte, bool) {
+	if h, ok := w.prev[relPath]; ok && h == hash && size == int64(len(content)) {
+		return nil, true
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return existing, string(existing) == content
+}
+
+func (w *Writer) count(f func(*WriteStats)) {
+	w.mu.Lock()
+	f(&w.stats)
+	w.mu.Unlock()
+}
+
+// Stats returns the counts of pages written so far.
+func (w *Writer) Stats() WriteStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
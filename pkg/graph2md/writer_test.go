@@ -0,0 +1,68 @@
+package graph2md
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriterRestampsProvenanceOnlyChanges(t *testing.T) {
+	page := func(generatedAt, body string) string {
+		return "---\ntitle: \"login\"\n" + generatorMarker + "\ncommit: \"abc\"\ngenerated_at: \"" + generatedAt + "\"\n---\n\n" + body
+	}
+	dir := t.TempDir()
+	first := page("2026-01-01T00:00:00Z", "Logs in.\n")
+	if err := NewWriter(dir, nil).Write("login.md", first); err != nil {
+		t.Fatal(err)
+	}
+	prev := &Manifest{Pages: []ManifestPage{{OutputPath: "login.md", Hash: ContentHash(first)}}}
+
+	for _, tt := range []struct {
+		name    string
+		content string
+		want    WriteStats
+		written string // file content afterwards
+	}{
+		{"same content", first, WriteStats{Unchanged: 1}, first},
+		{"new generation time", page("2026-02-02T00:00:00Z", "Logs in.\n"), WriteStats{Restamped: 1}, page("2026-02-02T00:00:00Z", "Logs in.\n")},
+		{"new body", page("2026-02-02T00:00:00Z", "Logs in and out.\n"), WriteStats{Changed: 1}, page("2026-02-02T00:00:00Z", "Logs in and out.\n")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, p := range []*Manifest{prev, nil} {
+				w := NewWriter(dir, p)
+				if err := w.Write("login.md", tt.content); err != nil {
+					t.Fatal(err)
+				}
+				if got := w.Stats(); got != tt.want {
+					t.Errorf("stats = %v, want %v (with manifest: %t)", got, tt.want, p != nil)
+				}
+				data, err := os.ReadFile(filepath.Join(dir, "login.md"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != tt.written {
+					t.Errorf("file holds:\n%s\nwant:\n%s", data, tt.written)
+				}
+				// Later iterations compare against what is on disk now.
+				if err := os.WriteFile(filepath.Join(dir, "login.md"), []byte(first), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStableContent(t *testing.T) {
+	a := "---\ntitle: \"x\"\ncommit: \"abc\"\ngenerated_at: \"1\"\n---\n\ncommit: \"abc\" in the body\n"
+	b := "---\ntitle: \"x\"\ncommit: \"def\"\ngenerated_at: \"2\"\n---\n\ncommit: \"abc\" in the body\n"
+	c := "---\ntitle: \"x\"\ncommit: \"def\"\ngenerated_at: \"2\"\n---\n\ncommit: \"def\" in the body\n"
+	if stableContent(a) != stableContent(b) {
+		t.Error("pages differing only in provenance differ after stripping it")
+	}
+	if stableContent(b) == stableContent(c) {
+		t.Error("body lines that look like provenance were stripped")
+	}
+	if ContentHash(a) == ContentHash(b) {
+		t.Error("ContentHash ignores provenance; the manifest must record the page as written")
+	}
+}
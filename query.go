@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/supermodeltools/graph2md/pkg/graph2md"
+)
+
+// runQuery implements "graph2md query": it lists the entities matching the
+// given filters, with the slugs generate gives their pages.
+func runQuery(args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	inputFiles := fs.String("input", "", "Comma-separated paths to graph JSON file(s), optionally gzipped; - reads standard input. Prefix a path with name= to slug its entities as generate does for repo name")
+	var q graph2md.Query
+	fs.StringVar(&q.ID, "id", "", "Node ID")
+	fs.StringVar(&q.Type, "type", "", "Node type, e.g. Function or File")
+	fs.StringVar(&q.Name, "name", "", "Name pattern, e.g. 'login*'")
+	fs.StringVar(&q.Path, "path", "", "Source path pattern or directory, e.g. 'src/auth' or 'src/*/service.ts'")
+	fs.StringVar(&q.Domain, "domain", "", "Domain name")
+	limit := fs.Int("limit", 0, "Maximum number of entities to print (0 for all)")
+	asJSON := fs.Bool("json", false, "Print the matches as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: graph2md query -input graph.json [filters] [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Lists entities matching all of the given filters. Several inputs are merged first.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	_, g, nodeRepos := loadGraphs(*inputFiles)
+	idx := graph2md.BuildIndex(g.Nodes, g.Relationships)
+	entries, _ := graph2md.AssignRepoSlugs(g.Nodes, nodeRepos)
+	matches, err := q.Find(entries, idx)
+	if err != nil {
+		log.Fatalf("bad pattern: %v", err)
+	}
+	total := len(matches)
+	if *limit > 0 && total > *limit {
+		matches = matches[:*limit]
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			log.Fatalf("encoding matches: %v", err)
+		}
+		fmt.Printf("%s\n", data)
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TYPE\tNAME\tPATH\tDOMAIN\tSLUG")
+		for _, m := range matches {
+			domain := m.Domain
+			if m.Subdomain != "" {
+				domain += "/" + m.Subdomain
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", m.Type, m.Name, m.Path, domain, m.Slug)
+		}
+		tw.Flush()
+	}
+	if len(matches) < total {
+		fmt.Fprintf(os.Stderr, "%d of %d matches shown\n", len(matches), total)
+	}
+}
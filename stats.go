@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/supermodeltools/graph2md/pkg/graph2md"
+)
+
+// runStats implements "graph2md stats": it prints node and relationship
+// counts by type, computed from the loaded graphs.
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	inputFiles := fs.String("input", "", "Comma-separated paths to graph JSON file(s), optionally gzipped; - reads standard input")
+	asJSON := fs.Bool("json", false, "Print the counts as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: graph2md stats -input graph.json [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Prints node and relationship counts by type. Several inputs are merged first.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	_, g, _ := loadGraphs(*inputFiles)
+	stats := graph2md.ComputeStats(g.Nodes, g.Relationships)
+
+	if *asJSON {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			log.Fatalf("encoding stats: %v", err)
+		}
+		fmt.Printf("%s\n", data)
+		return
+	}
+	fmt.Printf("Nodes: %d\n", stats.NodeCount)
+	printCounts(stats.NodeTypes)
+	fmt.Printf("Relationships: %d\n", stats.RelationshipCount)
+	printCounts(stats.RelationshipTypes)
+	if unlabeled := stats.NodeCount - sum(stats.NodeTypes); unlabeled > 0 {
+		fmt.Fprintf(os.Stderr, "%d nodes have no labels\n", unlabeled)
+	}
+}
+
+// printCounts prints counts by type, largest first.
+func printCounts(counts map[string]int) {
+	types := make([]string, 0, len(counts))
+	width := 0
+	for t := range counts {
+		types = append(types, t)
+		width = max(width, len(t))
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	for _, t := range types {
+		fmt.Printf("  %-*s %d\n", width, t, counts[t])
+	}
+}
+
+func sum(counts map[string]int) int {
+	n := 0
+	for _, c := range counts {
+		n += c
+	}
+	return n
+}
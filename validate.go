@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/supermodeltools/graph2md/pkg/graph2md"
+)
+
+// runValidate implements "graph2md validate": it checks the loaded graphs
+// for problems and exits with 1 if any errors are found.
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	inputFiles := fs.String("input", "", "Comma-separated paths to graph JSON file(s), optionally gzipped; - reads standard input")
+	asJSON := fs.Bool("json", false, "Print the problems as JSON")
+	strict := fs.Bool("strict", false, "Exit with 1 on warnings too")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: graph2md validate -input graph.json [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Checks graphs for dangling relationships, nodes without labels or with\n")
+		fmt.Fprintf(fs.Output(), "unknown types or missing properties, relationships between unexpected node\n")
+		fmt.Fprintf(fs.Output(), "types, and stats that disagree with the graph. Several inputs are merged\n")
+		fmt.Fprintf(fs.Output(), "first. Exits with 1 if errors are found.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	inputs := parseInputs(*inputFiles)
+	results, g, _ := loadGraphs(*inputFiles)
+	problems := graph2md.Validate(g)
+	for i, r := range results {
+		for _, p := range graph2md.ValidateStats(r) {
+			if len(results) > 1 {
+				p.Message = inputs[i].path + ": " + p.Message
+			}
+			problems = append(problems, p)
+		}
+	}
+
+	var errs, warnings int
+	for _, p := range problems {
+		if p.Severity == graph2md.SeverityError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+
+	if *asJSON {
+		report := struct {
+			Errors   int                `json:"errors"`
+			Warnings int                `json:"warnings"`
+			Problems []graph2md.Problem `json:"problems"`
+		}{errs, warnings, problems}
+		if report.Problems == nil {
+			report.Problems = []graph2md.Problem{}
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("encoding problems: %v", err)
+		}
+		fmt.Printf("%s\n", data)
+	} else {
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		fmt.Fprintf(os.Stderr, "Checked %d nodes, %d relationships: %d errors, %d warnings\n", len(g.Nodes), len(g.Relationships), errs, warnings)
+	}
+
+	if errs > 0 || (*strict && warnings > 0) {
+		os.Exit(1)
+	}
+}